@@ -3,9 +3,12 @@ module github.com/hitsan/sigscope
 go 1.24.3
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/charmbracelet/bubbletea v1.3.10
 	github.com/charmbracelet/lipgloss v1.1.0
 	github.com/fsnotify/fsnotify v1.9.0
+	github.com/klauspost/compress v1.18.0
+	github.com/ulikunitz/xz v0.5.15
 )
 
 require (
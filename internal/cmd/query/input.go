@@ -0,0 +1,22 @@
+package query
+
+import (
+	"os"
+
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+// stdinName is the file name that stands for standard input
+const stdinName = "-"
+
+// parseInput parses a VCD file, or standard input for "-". Files are read
+// through the parse cache unless noCache is set.
+func parseInput(filename string, noCache bool) (*waveform.VCDFile, error) {
+	if filename == stdinName {
+		return waveform.ParseReader(os.Stdin)
+	}
+	if noCache {
+		return waveform.Parse(filename)
+	}
+	return waveform.DefaultCache().Parse(filename)
+}
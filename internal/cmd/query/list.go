@@ -2,67 +2,60 @@ package query
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
-	"sort"
 
-	"sigscope/internal/vcd"
+	"github.com/hitsan/sigscope/pkg/waveform"
 )
 
 // RunList executes the list command
 func RunList(args []string) error {
-	// Check for help flag
-	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
-		fmt.Fprintln(os.Stderr, `Usage: sigscope list <vcd-file>
+	// Parse flags
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, `Usage: sigscope list [OPTIONS] <vcd-file>
 
-List all signals in the VCD file with metadata.
+List all signals in the VCD file with metadata. A file of "-" reads the
+dump from standard input.
+
+Options:
+  --no-cache                   Parse the dump even if it is cached
+  -h, --help                   Show this help message
 
 Output Format:
   JSON with signal names, widths, timescale, and time range.
 
 Examples:
   sigscope list waveform.vcd                    # List all signals
-  sigscope list waveform.vcd | jq '.signals'    # Extract signals array`)
-		return nil
+  sigscope list waveform.vcd | jq '.signals'    # Extract signals array
+  vvp sim | sigscope list -                     # Read the dump from a pipe`)
 	}
 
-	if len(args) < 1 {
+	var noCache bool
+	fs.BoolVar(&noCache, "no-cache", false, "Parse the dump even if it is cached")
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	if fs.NArg() < 1 {
 		return fmt.Errorf("usage: sigscope list <vcd-file>")
 	}
 
-	filename := args[0]
+	filename := fs.Arg(0)
 
 	// Parse VCD file
-	vcdFile, err := vcd.Parse(filename)
+	vcdFile, err := parseInput(filename, noCache)
 	if err != nil {
 		return fmt.Errorf("failed to parse VCD file: %w", err)
 	}
 
-	// Build signal list
-	signalList := vcdFile.GetSignalList()
-	signals := make([]SignalInfo, 0, len(signalList))
-
-	for _, sig := range signalList {
-		signals = append(signals, SignalInfo{
-			Name:  sig.Signal.FullName,
-			Width: sig.Signal.Width,
-		})
-	}
-
-	// Sort by name for consistent output
-	sort.Slice(signals, func(i, j int) bool {
-		return signals[i].Name < signals[j].Name
-	})
-
-	// Build output
-	output := ListOutput{
-		Signals:   signals,
-		Timescale: vcdFile.Timescale,
-		TimeRange: [2]uint64{0, vcdFile.EndTime},
-	}
-
 	// Output JSON
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(output)
+	return encoder.Encode(waveform.List(vcdFile))
 }
@@ -5,11 +5,9 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"sort"
-	"strconv"
 	"strings"
 
-	"sigscope/internal/vcd"
+	"github.com/hitsan/sigscope/pkg/waveform"
 )
 
 // stringSlice is a custom flag type for repeated string flags
@@ -32,11 +30,13 @@ func Run(args []string) error {
 		fmt.Fprintln(os.Stderr, `Usage: sigscope query [OPTIONS] <vcd-file>
 
 Query waveform data in differential event format optimized for LLM consumption.
+A file of "-" reads the dump from standard input.
 
 Options:
   -s, --signals <pattern>      Signal name pattern (can be repeated for multiple patterns)
   -t, --time-start <time>      Start time (default: 0)
   -e, --time-end <time>        End time (default: VCD end time)
+  --no-cache                   Parse the dump even if it is cached
   -h, --help                   Show this help message
 
 Output Format:
@@ -47,7 +47,8 @@ Examples:
   sigscope query waveform.vcd                         # All signals, full time range
   sigscope query -s clk -s data waveform.vcd          # Specific signals only
   sigscope query -t 1000 -e 5000 waveform.vcd         # Time range [1000, 5000]
-  sigscope query -s "udp_rx" waveform.vcd             # Partial name match`)
+  sigscope query -s "udp_rx" waveform.vcd             # Partial name match
+  vvp sim | sigscope query -s clk -                   # Read the dump from a pipe`)
 	}
 
 	var signals stringSlice
@@ -62,6 +63,9 @@ Examples:
 	fs.Uint64Var(&timeEnd, "e", 0, "End time (0 = use VCD end time)")
 	fs.Uint64Var(&timeEnd, "time-end", 0, "End time (0 = use VCD end time)")
 
+	var noCache bool
+	fs.BoolVar(&noCache, "no-cache", false, "Parse the dump even if it is cached")
+
 	if err := fs.Parse(args); err != nil {
 		if err == flag.ErrHelp {
 			return nil
@@ -77,7 +81,7 @@ Examples:
 	filename := fs.Arg(0)
 
 	// Parse VCD file
-	vcdFile, err := vcd.Parse(filename)
+	vcdFile, err := parseInput(filename, noCache)
 	if err != nil {
 		return fmt.Errorf("failed to parse VCD file: %w", err)
 	}
@@ -87,269 +91,12 @@ Examples:
 		timeEnd = vcdFile.EndTime
 	}
 
-	// Validate time range
-	if timeStart > timeEnd {
-		return fmt.Errorf("invalid time range: start (%d) > end (%d)", timeStart, timeEnd)
-	}
-
-	// Match signals
-	matchedSignals := matchSignals(vcdFile, signals)
-
-	// Detect clock from all signals (not just matched ones)
-	allSignals := vcdFile.GetSignalList()
-	clock := detectClock(allSignals, timeStart, timeEnd)
-
-	// Build signal definitions
-	defs := buildDefs(matchedSignals)
-
-	// Build initial values
-	init := buildInit(matchedSignals, timeStart)
-
-	// Build events
-	events := buildEvents(matchedSignals, timeStart, timeEnd, clock)
-
-	// Build output
-	output := QueryOutput{
-		Timescale: vcdFile.Timescale,
-		Defs:      defs,
-		Clock:     clock,
-		Init:      init,
-		Events:    events,
+	output, err := waveform.Query(vcdFile, signals, timeStart, timeEnd)
+	if err != nil {
+		return err
 	}
 
 	// Output JSON (compact, no indentation)
 	encoder := json.NewEncoder(os.Stdout)
 	return encoder.Encode(output)
 }
-
-// matchSignals filters signals based on patterns
-func matchSignals(vcdFile *vcd.VCDFile, patterns []string) []*vcd.SignalData {
-	allSignals := vcdFile.GetSignalList()
-
-	// If no patterns, return all signals
-	if len(patterns) == 0 {
-		return allSignals
-	}
-
-	// Filter by patterns
-	var matched []*vcd.SignalData
-	for _, sig := range allSignals {
-		for _, pattern := range patterns {
-			if strings.Contains(sig.Signal.FullName, pattern) {
-				matched = append(matched, sig)
-				break
-			}
-		}
-	}
-
-	return matched
-}
-
-// detectClock attempts to detect a clock signal
-func detectClock(signals []*vcd.SignalData, startTime, endTime uint64) *ClockInfo {
-	for _, sig := range signals {
-		// Only consider 1-bit signals
-		if sig.Signal.Width != 1 {
-			continue
-		}
-
-		// Collect transitions in the time range
-		var transitions []uint64
-		for _, ch := range sig.Changes {
-			if ch.Time >= startTime && ch.Time <= endTime {
-				transitions = append(transitions, ch.Time)
-			}
-		}
-
-		// Need at least 3 transitions to detect a period
-		if len(transitions) < 3 {
-			continue
-		}
-
-		// Check if intervals are periodic
-		intervals := make([]uint64, len(transitions)-1)
-		for i := 1; i < len(transitions); i++ {
-			intervals[i-1] = transitions[i] - transitions[i-1]
-		}
-
-		// Find the most common interval (period/2)
-		intervalCounts := make(map[uint64]int)
-		for _, interval := range intervals {
-			intervalCounts[interval]++
-		}
-
-		var maxCount int
-		var halfPeriod uint64
-		for interval, count := range intervalCounts {
-			if count > maxCount {
-				maxCount = count
-				halfPeriod = interval
-			}
-		}
-
-		// If majority of intervals match, we found a clock
-		if maxCount >= len(intervals)*2/3 {
-			// Determine edge by checking first transition value
-			edge := "posedge"
-			if len(sig.Changes) > 0 {
-				firstVal := sig.Changes[0].Value
-				if firstVal == "1" {
-					edge = "negedge"
-				}
-			}
-
-			return &ClockInfo{
-				Name:   shortName(sig.Signal.FullName),
-				Period: halfPeriod * 2,
-				Edge:   edge,
-			}
-		}
-	}
-
-	return nil
-}
-
-// buildDefs constructs the signal definitions map
-func buildDefs(signals []*vcd.SignalData) map[string]SignalDef {
-	defs := make(map[string]SignalDef)
-
-	for _, sig := range signals {
-		name := shortName(sig.Signal.FullName)
-		def := SignalDef{
-			Width: sig.Signal.Width,
-		}
-
-		// Only set radix for multi-bit signals
-		if sig.Signal.Width > 1 {
-			// Determine radix by checking if any value contains x/z
-			hasXZ := false
-			for _, ch := range sig.Changes {
-				if strings.ContainsAny(ch.Value, "xXzZ") {
-					hasXZ = true
-					break
-				}
-			}
-
-			if hasXZ {
-				def.Radix = "bin"
-			} else {
-				def.Radix = "hex"
-			}
-		}
-
-		defs[name] = def
-	}
-
-	return defs
-}
-
-// buildInit constructs the initial value map
-func buildInit(signals []*vcd.SignalData, startTime uint64) map[string]string {
-	init := make(map[string]string)
-
-	for _, sig := range signals {
-		name := shortName(sig.Signal.FullName)
-		value := sig.GetValueAt(startTime)
-		init[name] = formatValue(value, sig.Signal.Width)
-	}
-
-	return init
-}
-
-// Change represents a signal value change
-type Change struct {
-	Time   uint64
-	Signal string
-	Value  string
-}
-
-// buildEvents constructs the event list
-func buildEvents(signals []*vcd.SignalData, startTime, endTime uint64, clock *ClockInfo) []Event {
-	var changes []Change
-
-	for _, sig := range signals {
-		name := shortName(sig.Signal.FullName)
-
-		// Skip clock signal
-		if clock != nil && name == clock.Name {
-			continue
-		}
-
-		// Collect changes in time range
-		for _, ch := range sig.Changes {
-			if ch.Time >= startTime && ch.Time <= endTime {
-				changes = append(changes, Change{
-					Time:   ch.Time,
-					Signal: name,
-					Value:  formatValue(ch.Value, sig.Signal.Width),
-				})
-			}
-		}
-	}
-
-	// Sort by time
-	sort.Slice(changes, func(i, j int) bool {
-		return changes[i].Time < changes[j].Time
-	})
-
-	// Group by time
-	var events []Event
-	if len(changes) == 0 {
-		return events
-	}
-
-	currentTime := changes[0].Time
-	currentSet := make(map[string]string)
-
-	for _, ch := range changes {
-		if ch.Time != currentTime {
-			events = append(events, Event{
-				Time: currentTime,
-				Set:  currentSet,
-			})
-			currentTime = ch.Time
-			currentSet = make(map[string]string)
-		}
-		currentSet[ch.Signal] = ch.Value
-	}
-
-	// Add last event
-	if len(currentSet) > 0 {
-		events = append(events, Event{
-			Time: currentTime,
-			Set:  currentSet,
-		})
-	}
-
-	return events
-}
-
-// shortName extracts the last component of a hierarchical name
-func shortName(fullName string) string {
-	idx := strings.LastIndex(fullName, ".")
-	if idx == -1 {
-		return fullName
-	}
-	return fullName[idx+1:]
-}
-
-// formatValue formats a value based on width and returns plain string
-func formatValue(value string, width int) string {
-	if width == 1 {
-		return value // "0", "1", "x", "z"
-	}
-
-	// Check for x/z - return as binary
-	if strings.ContainsAny(value, "xXzZ") {
-		return value
-	}
-
-	// Convert to hex
-	n, err := strconv.ParseUint(value, 2, 64)
-	if err != nil {
-		// Fallback to binary
-		return value
-	}
-
-	return fmt.Sprintf("%X", n)
-}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hitsan/sigscope/internal/render"
+
+	"github.com/BurntSushi/toml"
+)
+
+// minSignalPaneWidth keeps room for the row marker and a few characters
+const minSignalPaneWidth = 8
+
+// Config holds user preferences read from config.toml
+type Config struct {
+	SignalPaneWidth int               // Width of the signal name pane
+	Radix           render.Radix      // Default radix of buses
+	TallMode        bool              // Start in multi-row waveform mode
+	Cursor          bool              // Show the cursor at startup
+	Overview        bool              // Show the overview bar at startup
+	Theme           string            // Color theme name ("" = default)
+	Run             string            // Rebuild command started by the run key
+	Keys            map[string]string // Key -> action name, on top of the default bindings
+}
+
+// Default returns the built-in preferences
+func Default() Config {
+	return Config{
+		SignalPaneWidth: 22,
+		Radix:           render.RadixHex,
+		Cursor:          true,
+		Overview:        true,
+		Keys:            map[string]string{},
+	}
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/sigscope/config.toml, falling back
+// to ~/.config/sigscope/config.toml
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "sigscope", "config.toml")
+}
+
+// file is the layout of config.toml. Settings are pointers so that those
+// left out keep their defaults.
+type file struct {
+	SignalPaneWidth *int              `toml:"signal_pane_width"`
+	Radix           *string           `toml:"radix"`
+	Tall            *bool             `toml:"tall"`
+	Cursor          *bool             `toml:"cursor"`
+	Overview        *bool             `toml:"overview"`
+	Theme           *string           `toml:"theme"`
+	Run             *string           `toml:"run"`
+	Keys            map[string]string `toml:"keys"`
+}
+
+// Load reads a config file on top of the defaults
+func Load(path string) (Config, error) {
+	cfg := Default()
+	f, err := os.Open(path)
+	if err != nil {
+		return cfg, err
+	}
+	defer f.Close()
+
+	var settings file
+	md, err := toml.NewDecoder(f).Decode(&settings)
+	if err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return cfg, fmt.Errorf("%s: unknown setting %q", path, undecoded[0].String())
+	}
+	if err := cfg.apply(settings); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// apply sets the preferences given in a config file
+func (c *Config) apply(f file) error {
+	if f.SignalPaneWidth != nil {
+		if *f.SignalPaneWidth < minSignalPaneWidth {
+			return fmt.Errorf("signal_pane_width must be at least %d", minSignalPaneWidth)
+		}
+		c.SignalPaneWidth = *f.SignalPaneWidth
+	}
+	if f.Radix != nil {
+		radix, ok := render.ParseRadix(*f.Radix)
+		if !ok {
+			return fmt.Errorf("unknown radix %q", *f.Radix)
+		}
+		c.Radix = radix
+	}
+	set(&c.TallMode, f.Tall)
+	set(&c.Cursor, f.Cursor)
+	set(&c.Overview, f.Overview)
+	set(&c.Theme, f.Theme)
+	set(&c.Run, f.Run)
+	for key, action := range f.Keys {
+		c.Keys[key] = action
+	}
+	return nil
+}
+
+// set sets a preference if the config file gives it
+func set[T any](pref *T, v *T) {
+	if v != nil {
+		*pref = *v
+	}
+}
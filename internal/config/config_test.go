@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hitsan/sigscope/internal/render"
+)
+
+// writeConfig writes a config file in a test's temporary directory
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+signal_pane_width = 30
+radix = "dec"
+tall = true
+cursor = false
+overview = false
+theme = 'light' # Literal string
+run = "make sim"
+
+[keys]
+"ctrl+o" = "zoom_out"
+j = ""
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{
+		SignalPaneWidth: 30,
+		Radix:           render.RadixDec,
+		TallMode:        true,
+		Theme:           "light",
+		Run:             "make sim",
+		Keys:            map[string]string{"ctrl+o": "zoom_out", "j": ""},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("config %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "# nothing set\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("config %+v, want the defaults", cfg)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.toml")); !os.IsNotExist(err) {
+		t.Errorf("missing file: error %v, want not exist", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"signal_pane_width = 4", "signal_pane_width must be at least 8"},
+		{"signal_pane_width = \"wide\"", `line 1 (last key "signal_pane_width"): incompatible types`},
+		{"radix = \"oct\"", `unknown radix "oct"`},
+		{"tall = 1", `line 1 (last key "tall"): incompatible types`},
+		{"colour = \"red\"", `unknown setting "colour"`},
+		{"[keys]\nq = 1", `line 2 (last key "keys.q"): incompatible types`},
+		{"[mouse]\nwheel = true", `unknown setting "mouse"`},
+		{"a = ", "line 1 (last key \"a\"): unexpected EOF"},
+	}
+	for _, tt := range tests {
+		path := writeConfig(t, tt.input)
+		_, err := Load(path)
+		if err == nil || !strings.HasPrefix(err.Error(), path+": ") || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Load(%q): error %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
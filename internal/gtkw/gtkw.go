@@ -0,0 +1,428 @@
+package gtkw
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hitsan/sigscope/internal/session"
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+// Trace flag bits used in "@" lines (see GTKWave's TR_* definitions)
+const (
+	flagHex      = 1 << 1
+	flagDec      = 1 << 2
+	flagBin      = 1 << 3
+	flagCollapse = 1 << 22
+	flagGrpBegin = 1 << 23
+	flagGrpEnd   = 1 << 24
+)
+
+// defaultWavePixels is assumed for the waveform area when the save file has no [size]
+const defaultWavePixels = 800
+
+// TraceKind identifies the type of an entry in the trace list
+type TraceKind int
+
+const (
+	TraceSignal     TraceKind = iota // A signal (possibly with a bit range)
+	TraceConcat                      // #{name} concatenation of signals
+	TraceComment                     // Comment or blank row
+	TraceGroupBegin                  // Start of a named group
+	TraceGroupEnd                    // End of a group
+)
+
+// Trace is one entry of the GTKWave trace list
+type Trace struct {
+	Kind  TraceKind
+	Name  string   // Signal name, concatenation name or comment text
+	Bits  []string // Members of a concatenation, MSB first
+	Flags uint64   // Flags from the preceding "@" line
+}
+
+// SaveFile holds the parts of a .gtkw save file that sigscope understands
+type SaveFile struct {
+	DumpFile     string
+	TimeStart    uint64
+	Zoom         float64 // log2 zoom; time per pixel is 2^-Zoom
+	HasZoom      bool
+	Marker       int64            // Primary marker, -1 if unset
+	NamedMarkers map[string]int64 // Named markers A-Z
+	WindowWidth  int
+	SignalsWidth int
+	Traces       []Trace
+}
+
+// Load reads a .gtkw save file
+func Load(path string) (*SaveFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sf, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gtkw file %s: %w", path, err)
+	}
+	return sf, nil
+}
+
+// Parse reads a GTKWave save file from r
+func Parse(r io.Reader) (*SaveFile, error) {
+	sf := &SaveFile{
+		Marker:       -1,
+		NamedMarkers: make(map[string]int64),
+	}
+
+	scanner := bufio.NewScanner(r)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	var flags uint64
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "[*]"):
+			// Comment line
+		case strings.HasPrefix(line, "["):
+			parseOption(sf, line)
+		case strings.HasPrefix(line, "*"):
+			parseZoomLine(sf, line)
+		case strings.HasPrefix(line, "@"):
+			f, err := strconv.ParseUint(line[1:], 16, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid flags line %q", line)
+			}
+			flags = f
+		case strings.HasPrefix(line, "-"):
+			sf.Traces = append(sf.Traces, commentTrace(line[1:], flags))
+		case strings.HasPrefix(line, "#{"):
+			if t, ok := parseConcat(line); ok {
+				t.Flags = flags
+				sf.Traces = append(sf.Traces, t)
+			}
+		case strings.HasPrefix(line, "+{"):
+			// Aliased signal: "+{alias} name"
+			if idx := strings.Index(line, "} "); idx != -1 {
+				sf.Traces = append(sf.Traces, Trace{Kind: TraceSignal, Name: strings.TrimSpace(line[idx+2:]), Flags: flags})
+			}
+		case strings.HasPrefix(line, "^"):
+			// Translate filters are not supported
+		default:
+			sf.Traces = append(sf.Traces, Trace{Kind: TraceSignal, Name: line, Flags: flags})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sf, nil
+}
+
+// parseOption handles "[key] value" header lines
+func parseOption(sf *SaveFile, line string) {
+	end := strings.Index(line, "]")
+	if end == -1 {
+		return
+	}
+	key := line[1:end]
+	fields := strings.Fields(line[end+1:])
+
+	switch key {
+	case "dumpfile":
+		sf.DumpFile = strings.Trim(strings.TrimSpace(line[end+1:]), `"`)
+	case "timestart":
+		if len(fields) > 0 {
+			sf.TimeStart, _ = strconv.ParseUint(fields[0], 10, 64)
+		}
+	case "size":
+		if len(fields) > 0 {
+			sf.WindowWidth, _ = strconv.Atoi(fields[0])
+		}
+	case "signals_width":
+		if len(fields) > 0 {
+			sf.SignalsWidth, _ = strconv.Atoi(fields[0])
+		}
+	}
+}
+
+// parseZoomLine handles "*zoom marker named_markers..." lines
+func parseZoomLine(sf *SaveFile, line string) {
+	fields := strings.Fields(line[1:])
+	if len(fields) == 0 {
+		return
+	}
+	if z, err := strconv.ParseFloat(fields[0], 64); err == nil {
+		sf.Zoom = z
+		sf.HasZoom = true
+	}
+	if len(fields) > 1 {
+		if mk, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			sf.Marker = mk
+		}
+	}
+	for i, field := range fields[2:] {
+		if i >= 26 {
+			break
+		}
+		if t, err := strconv.ParseInt(field, 10, 64); err == nil && t >= 0 {
+			sf.NamedMarkers[string(rune('A'+i))] = t
+		}
+	}
+}
+
+// commentTrace builds a comment, blank or group trace from a "-" line
+func commentTrace(text string, flags uint64) Trace {
+	kind := TraceComment
+	switch {
+	case flags&flagGrpBegin != 0:
+		kind = TraceGroupBegin
+	case flags&flagGrpEnd != 0:
+		kind = TraceGroupEnd
+	}
+	return Trace{Kind: kind, Name: text, Flags: flags}
+}
+
+// parseConcat parses "#{name} sig1 sig2 ..." concatenation lines
+func parseConcat(line string) (Trace, bool) {
+	end := strings.Index(line, "}")
+	if end == -1 {
+		return Trace{}, false
+	}
+	return Trace{
+		Kind: TraceConcat,
+		Name: line[2:end],
+		Bits: strings.Fields(line[end+1:]),
+	}, true
+}
+
+// radixName maps trace flags to a sigscope radix name
+func radixName(flags uint64) string {
+	switch {
+	case flags&flagBin != 0:
+		return "bin"
+	case flags&flagDec != 0:
+		return "dec"
+	case flags&flagHex != 0:
+		return "hex"
+	}
+	return ""
+}
+
+// ToSession maps the save file onto a sigscope session for vcdFile.
+// Times are converted from GTKWave's units back to dump units, bit selects
+// become expanded bus rows, concatenations or partial ranges become virtual
+// buses, comments become dividers and groups keep their open/closed state.
+func (sf *SaveFile) ToSession(vcdFile *waveform.VCDFile) *session.Session {
+	scale, _, err := waveform.ParseTimescale(vcdFile.Timescale)
+	if err != nil {
+		scale = 1
+	}
+	s := &session.Session{
+		Version:   session.CurrentVersion,
+		Dump:      sf.DumpFile,
+		TimeStart: sf.TimeStart / scale,
+	}
+
+	r := newResolver(vcdFile)
+	var groups []*session.Entry // Open groups, innermost last
+	addEntry := func(e session.Entry) {
+		if len(groups) == 0 {
+			s.Layout = append(s.Layout, e)
+			return
+		}
+		parent := groups[len(groups)-1]
+		parent.Children = append(parent.Children, e)
+	}
+
+	for _, t := range sf.Traces {
+		switch t.Kind {
+		case TraceSignal:
+			name, bits := r.resolve(t.Name)
+			if bits != nil {
+				// Partial range: show it as a virtual bus
+				s.VirtualBuses = append(s.VirtualBuses, session.VirtualBus{Name: name, Bits: bits})
+			}
+			s.Signals = append(s.Signals, session.Signal{Name: name, Radix: radixName(t.Flags)})
+			addEntry(session.Entry{Type: session.EntrySignal, Name: name})
+		case TraceConcat:
+			vb := session.VirtualBus{Name: t.Name}
+			for _, member := range t.Bits {
+				vb.Bits = append(vb.Bits, r.bits(member)...)
+			}
+			s.VirtualBuses = append(s.VirtualBuses, vb)
+			s.Signals = append(s.Signals, session.Signal{Name: t.Name, Radix: radixName(t.Flags)})
+			addEntry(session.Entry{Type: session.EntrySignal, Name: t.Name})
+		case TraceComment:
+			addEntry(session.Entry{Type: session.EntryDivider, Name: t.Name})
+		case TraceGroupBegin:
+			groups = append(groups, &session.Entry{
+				Type:      session.EntryGroup,
+				Name:      t.Name,
+				Collapsed: t.Flags&flagCollapse != 0,
+			})
+		case TraceGroupEnd:
+			if len(groups) == 0 {
+				continue
+			}
+			group := groups[len(groups)-1]
+			groups = groups[:len(groups)-1]
+			addEntry(*group)
+		}
+	}
+	// Close groups left open at the end of the file
+	for len(groups) > 0 {
+		group := groups[len(groups)-1]
+		groups = groups[:len(groups)-1]
+		addEntry(*group)
+	}
+	s.ExpandedBuses = r.expandedBuses()
+
+	if sf.Marker >= 0 {
+		s.Cursor = uint64(sf.Marker) / scale
+	}
+	for name, t := range sf.NamedMarkers {
+		s.Markers = append(s.Markers, session.Marker{Name: name, Time: uint64(t) / scale})
+	}
+	sort.Slice(s.Markers, func(i, j int) bool {
+		return s.Markers[i].Name < s.Markers[j].Name
+	})
+
+	if sf.HasZoom {
+		pixels := sf.WindowWidth - sf.SignalsWidth
+		if pixels <= 0 {
+			pixels = defaultWavePixels
+		}
+		duration := float64(pixels) * math.Pow(2, -sf.Zoom) / float64(scale)
+		if duration >= 1 && s.TimeStart < vcdFile.EndTime {
+			s.TimeEnd = min(s.TimeStart+uint64(duration), vcdFile.EndTime)
+		}
+	}
+	return s
+}
+
+// resolver maps GTKWave trace names onto sigscope signal names
+type resolver struct {
+	widths   map[string]int // Signal width by full name
+	expanded map[string]bool
+}
+
+func newResolver(vcdFile *waveform.VCDFile) *resolver {
+	r := &resolver{
+		widths:   make(map[string]int, len(vcdFile.Signals)),
+		expanded: make(map[string]bool),
+	}
+	for _, sd := range vcdFile.Signals {
+		r.widths[sd.Signal.FullName] = sd.Signal.Width
+	}
+	return r
+}
+
+// resolve returns the sigscope row name for a trace. A range that does not
+// cover the whole bus also returns its bit names, to be built as a virtual bus.
+func (r *resolver) resolve(name string) (string, []string) {
+	if _, ok := r.widths[name]; ok {
+		return name, nil
+	}
+	base, hi, lo, ok := splitBitRange(name)
+	if !ok {
+		return name, nil
+	}
+	if hi == lo {
+		r.expanded[base] = true
+		return bitName(base, hi), nil
+	}
+	if r.widths[base] == span(hi, lo) {
+		return base, nil
+	}
+	return fmt.Sprintf("%s_%d_%d", base, hi, lo), r.bits(name)
+}
+
+// bits returns the 1-bit signal names a trace refers to, MSB first
+func (r *resolver) bits(name string) []string {
+	if width, ok := r.widths[name]; ok && width == 1 {
+		return []string{name}
+	}
+	base, hi, lo, ok := splitBitRange(name)
+	if !ok {
+		// Whole bus without a range in its name
+		width := r.widths[name]
+		if width <= 1 {
+			return []string{name}
+		}
+		base, hi, lo = name, width-1, 0
+	}
+
+	r.expanded[base] = true
+	step := -1
+	if hi < lo {
+		step = 1
+	}
+	var bits []string
+	for b := hi; ; b += step {
+		bits = append(bits, bitName(base, b))
+		if b == lo {
+			break
+		}
+	}
+	return bits
+}
+
+// expandedBuses returns the buses that need bit rows, sorted by name
+func (r *resolver) expandedBuses() []string {
+	var names []string
+	for name := range r.expanded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// splitBitRange splits "top.data[7:0]" or "top.data[3]" into the base name
+// and the selected MSB and LSB
+func splitBitRange(name string) (base string, hi, lo int, ok bool) {
+	if !strings.HasSuffix(name, "]") {
+		return name, 0, 0, false
+	}
+	open := strings.LastIndex(name, "[")
+	if open == -1 {
+		return name, 0, 0, false
+	}
+	base = strings.TrimSpace(name[:open])
+	spec := name[open+1 : len(name)-1]
+
+	msb, lsb, found := strings.Cut(spec, ":")
+	if !found {
+		lsb = msb
+	}
+	hi, err1 := strconv.Atoi(msb)
+	lo, err2 := strconv.Atoi(lsb)
+	if err1 != nil || err2 != nil {
+		return name, 0, 0, false
+	}
+	return base, hi, lo, true
+}
+
+// span returns the number of bits in [hi:lo]
+func span(hi, lo int) int {
+	if hi < lo {
+		hi, lo = lo, hi
+	}
+	return hi - lo + 1
+}
+
+// bitName returns the name sigscope uses for a bit row of an expanded bus
+func bitName(bus string, bit int) string {
+	return fmt.Sprintf("%s[%d]", bus, bit)
+}
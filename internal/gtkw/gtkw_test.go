@@ -0,0 +1,179 @@
+package gtkw
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hitsan/sigscope/internal/session"
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+const testSave = `[*]
+[*] GTKWave Analyzer v3.3.104 (w)1999-2020 BSI
+[*]
+[dumpfile] "/home/user/sim/tb.vcd"
+[timestart] 200
+[size] 1200 600
+[signals_width] 200
+*-3.000000 500 300 -1 700
+[treeopen] tb.
+@28
+tb.clk
+@22
+tb.data[7:0]
+-
+@800200
+-ctrl
+@28
+tb.data[3]
++{enable} tb.en
+@1000200
+-ctrl
+@24
+#{tb.pair} tb.en tb.clk
+@8
+tb.data[5:2]
+^1 filter.txt
+`
+
+func TestParse(t *testing.T) {
+	sf, err := Parse(strings.NewReader(testSave))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sf.DumpFile != "/home/user/sim/tb.vcd" || sf.TimeStart != 200 {
+		t.Errorf("dump file %q, time start %d", sf.DumpFile, sf.TimeStart)
+	}
+	if sf.WindowWidth != 1200 || sf.SignalsWidth != 200 {
+		t.Errorf("widths %d and %d, want 1200 and 200", sf.WindowWidth, sf.SignalsWidth)
+	}
+	if !sf.HasZoom || sf.Zoom != -3 || sf.Marker != 500 {
+		t.Errorf("zoom %v (%v), marker %d", sf.Zoom, sf.HasZoom, sf.Marker)
+	}
+	if want := map[string]int64{"A": 300, "C": 700}; !reflect.DeepEqual(sf.NamedMarkers, want) {
+		t.Errorf("named markers %v, want %v", sf.NamedMarkers, want)
+	}
+
+	want := []Trace{
+		{Kind: TraceSignal, Name: "tb.clk", Flags: 0x28},
+		{Kind: TraceSignal, Name: "tb.data[7:0]", Flags: 0x22},
+		{Kind: TraceComment, Name: "", Flags: 0x22},
+		{Kind: TraceGroupBegin, Name: "ctrl", Flags: 0x800200},
+		{Kind: TraceSignal, Name: "tb.data[3]", Flags: 0x28},
+		{Kind: TraceSignal, Name: "tb.en", Flags: 0x28},
+		{Kind: TraceGroupEnd, Name: "ctrl", Flags: 0x1000200},
+		{Kind: TraceConcat, Name: "tb.pair", Bits: []string{"tb.en", "tb.clk"}, Flags: 0x24},
+		{Kind: TraceSignal, Name: "tb.data[5:2]", Flags: 0x8},
+	}
+	if !reflect.DeepEqual(sf.Traces, want) {
+		t.Errorf("traces\n%+v\nwant\n%+v", sf.Traces, want)
+	}
+}
+
+func TestParseInvalidFlags(t *testing.T) {
+	if _, err := Parse(strings.NewReader("@zz\ntb.clk\n")); err == nil {
+		t.Error("no error for an invalid flags line")
+	}
+}
+
+func TestSplitBitRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		hi, lo int
+		ok     bool
+	}{
+		{"tb.data[7:0]", "tb.data", 7, 0, true},
+		{"tb.data[0:7]", "tb.data", 0, 7, true},
+		{"tb.data[3]", "tb.data", 3, 3, true},
+		{"tb.data [3]", "tb.data", 3, 3, true},
+		{"tb.data", "tb.data", 0, 0, false},
+		{"tb.data[a:0]", "tb.data[a:0]", 0, 0, false},
+		{"tb.data]", "tb.data]", 0, 0, false},
+	}
+	for _, tt := range tests {
+		base, hi, lo, ok := splitBitRange(tt.name)
+		if base != tt.base || hi != tt.hi || lo != tt.lo || ok != tt.ok {
+			t.Errorf("splitBitRange(%q) = %q, %d, %d, %v", tt.name, base, hi, lo, ok)
+		}
+	}
+}
+
+func TestToSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tb.vcd")
+	err := os.WriteFile(path, []byte(`$timescale 10ps $end
+$scope module tb $end
+$var wire 1 ! clk $end
+$var wire 1 # en $end
+$var wire 8 " data [7:0] $end
+$upscope $end
+$enddefinitions $end
+#0
+#1000
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := waveform.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf, err := Parse(strings.NewReader(testSave))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := sf.ToSession(v)
+
+	bits := func(hi, lo int) []string {
+		var names []string
+		for b := hi; b >= lo; b-- {
+			names = append(names, bitName("tb.data", b))
+		}
+		return names
+	}
+	want := &session.Session{
+		Version: session.CurrentVersion,
+		Dump:    "/home/user/sim/tb.vcd",
+		Signals: []session.Signal{
+			{Name: "tb.clk", Radix: "bin"},
+			{Name: "tb.data", Radix: "hex"},
+			{Name: "tb.data[3]", Radix: "bin"},
+			{Name: "tb.en", Radix: "bin"},
+			{Name: "tb.pair", Radix: "dec"},
+			{Name: "tb.data_5_2", Radix: "bin"},
+		},
+		Layout: []session.Entry{
+			{Type: session.EntrySignal, Name: "tb.clk"},
+			{Type: session.EntrySignal, Name: "tb.data"},
+			{Type: session.EntryDivider},
+			{Type: session.EntryGroup, Name: "ctrl", Collapsed: false, Children: []session.Entry{
+				{Type: session.EntrySignal, Name: "tb.data[3]"},
+				{Type: session.EntrySignal, Name: "tb.en"},
+			}},
+			{Type: session.EntrySignal, Name: "tb.pair"},
+			{Type: session.EntrySignal, Name: "tb.data_5_2"},
+		},
+		ExpandedBuses: []string{"tb.data"},
+		VirtualBuses: []session.VirtualBus{
+			{Name: "tb.pair", Bits: []string{"tb.en", "tb.clk"}},
+			{Name: "tb.data_5_2", Bits: bits(5, 2)},
+		},
+		// GTKWave times are in ps, the dump's unit is 10ps
+		Markers:   []session.Marker{{Name: "A", Time: 30}, {Name: "C", Time: 70}},
+		Cursor:    50,
+		TimeStart: 20,
+		// 1000 pixels at 8ps each
+		TimeEnd: 820,
+	}
+
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("session\n%s\nwant\n%s", gotJSON, wantJSON)
+	}
+}
@@ -1,11 +1,13 @@
 package model
 
 import (
-	"sort"
+	"fmt"
+	"path"
 	"strings"
 	"time"
 
-	"sigscope/internal/vcd"
+	"github.com/hitsan/sigscope/internal/render"
+	"github.com/hitsan/sigscope/pkg/waveform"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -16,45 +18,127 @@ type Mode int
 const (
 	ModeNormal Mode = iota
 	ModeSearch
+	ModePrompt
+	ModeCommand
+	ModeHelp
+	ModePicker
+)
+
+// PromptKind identifies what a text prompt is collecting input for
+type PromptKind int
+
+const (
+	PromptVirtualBus PromptKind = iota // Name of a new virtual bus
+	PromptDivider                      // Label of a new divider
+	PromptGroup                        // Name of a new group
+	PromptZoom                         // Time range or zoom level
+)
+
+// Prompt holds the state of a single-line text prompt
+type Prompt struct {
+	Kind  PromptKind
+	Label string
+	Input string
+}
+
+// Screen layout, in terminal lines and columns
+const (
+	HeaderLines  = 3 // Title, timeline and separator above the signal rows
+	FooterLines  = 1 // Status bar below the signal rows
+	OverviewLine = 1 // Line of the overview bar (when shown), below the title
 )
 
 // Model is the main application state
 type Model struct {
 	// VCD data
-	VCD      *vcd.VCDFile
-	Signals  []*vcd.SignalData // Sorted signal list
-	Filename string
+	VCD         *waveform.VCDFile
+	Signals     []*waveform.SignalData // Sorted signal list
+	Filename    string
+	signalIndex map[string]int // Full name -> index in Signals
 
 	// Viewport state
-	TimeStart   uint64  // Start time of visible window
-	TimeEnd     uint64  // End time of visible window
-	Zoom        float64 // Zoom level (1.0 = default)
-	TimePerChar uint64  // Time units per character
+	TimeStart uint64  // Start time of visible window
+	TimeEnd   uint64  // End time of visible window
+	Zoom      float64 // Zoom level (1.0 = whole dump), derived from the window
 
 	// Cursor state
 	CursorTime    uint64 // Current cursor position in time
 	CursorVisible bool
 
 	// Selection state
-	SelectedSignal int // Index of selected signal
+	SelectedSignal int    // Index of selected signal (-1 when a divider or group is selected)
+	selectedEntry  *Entry // Selected divider or group header
+
+	// Display order: signals, dividers and groups
+	Layout []*Entry
+	rows   *rowCache // Rows of Layout in the current mode
 
 	// Signal visibility
 	SignalVisible []bool // 各信号の表示/非表示（Signalsと同じ長さ）
 	SelectMode    bool   // true: 全信号選択モード
 
+	// Derived signals
+	ExpandedBuses map[string]bool   // Full names of buses expanded into bits
+	VirtualBuses  []VirtualBus      // User-defined buses built from 1-bit signals
+	PickedSignals []string          // 1-bit signals picked for a new virtual bus, MSB first
+	bitParent     map[string]string // Bit row full name -> parent bus full name
+	derivedFrom   []int             // Changes of each signal the derived ones were built from
+
+	// Annotations
+	Markers []Marker                // Named time markers, sorted by time
+	Radix   map[string]render.Radix // Display radix per bus full name
+	Colors  map[string]string       // Color override per signal full name
+
+	// Session file used by the save key
+	SessionFile string
+
 	// Display state
-	Width           int // Terminal width
-	Height          int // Terminal height
-	SignalPaneWidth int // Width of signal name pane
+	Width           int               // Terminal width
+	Height          int               // Terminal height
+	SignalPaneWidth int               // Width of signal name pane
+	TallMode        bool              // true: multi-row waveform rendering
+	OverviewVisible bool              // Overview bar of the whole dump above the timeline
+	DefaultRadix    render.Radix      // Radix of buses without their own (hex if empty)
+	KeyMap          map[string]string // Key -> action name (default bindings if nil)
 
 	// Mode
 	Mode         Mode
 	SearchQuery  string
 	SearchResult []int // Indices of matching signals
+	Prompt       Prompt
+	Command      CommandLine // ":" command line and its history
+	Help         []HelpLine  // Lines of the help overlay
+	HelpScroll   int         // First help line shown
+
+	// One-shot message shown in the status bar
+	StatusMessage string
+
+	// Earlier views for back/forward navigation
+	History History
+
+	// Mouse drag-to-zoom state
+	Dragging      bool
+	DragStartTime uint64
+	DragEndTime   uint64
 
 	// Scroll state for signal list
 	SignalScrollOffset int
 
+	// Dumps of the directory watched with --dir
+	DumpDir   string     // "" when a single file is open
+	Dumps     []DumpFile // Newest first
+	PickerPos int        // Selected dump in the picker
+
+	// Rebuild command and its output
+	Run RunState
+
+	// Incremental reading of a dump that is still being written
+	Follower     *waveform.Follower // nil unless following
+	FollowPaused bool               // Keep the window still while the dump grows
+
+	// Parsed dumps kept between runs; nil parses every time
+	Cache *waveform.Cache
+
 	// File watching state
 	WatchError     string
 	ReloadError    string
@@ -62,47 +146,28 @@ type Model struct {
 }
 
 // NewModel creates a new Model with VCD data
-func NewModel(vcdFile *vcd.VCDFile, filename string) Model {
-	signals := vcdFile.GetSignalList()
-
-	// Sort signals by full name
-	sort.Slice(signals, func(i, j int) bool {
-		return signals[i].Signal.FullName < signals[j].Signal.FullName
-	})
-
-	// Initialize signal visibility (all visible by default)
-	signalVisible := make([]bool, len(signals))
-	for i := range signalVisible {
-		signalVisible[i] = true
-	}
-
-	// Calculate initial time per char (show entire waveform by default)
-	timePerChar := uint64(1)
-	if vcdFile.EndTime > 0 {
-		timePerChar = vcdFile.EndTime / 80 // Assume ~80 chars for waveform
-		if timePerChar < 1 {
-			timePerChar = 1
-		}
-	}
-
-	return Model{
+func NewModel(vcdFile *waveform.VCDFile, filename string) Model {
+	m := Model{
 		VCD:             vcdFile,
-		Signals:         signals,
 		Filename:        filename,
 		TimeStart:       0,
 		TimeEnd:         vcdFile.EndTime,
 		Zoom:            1.0,
-		TimePerChar:     timePerChar,
 		CursorTime:      0,
 		CursorVisible:   true,
 		SelectedSignal:  0,
-		SignalVisible:   signalVisible,
 		SelectMode:      false,
 		Width:           80,
 		Height:          24,
 		SignalPaneWidth: 22,
+		OverviewVisible: true,
 		Mode:            ModeNormal,
 	}
+
+	// Sort signals by full name (all visible by default)
+	m.rebuildSignals()
+	m.ensureSelection()
+	return m
 }
 
 // Init implements tea.Model
@@ -110,18 +175,74 @@ func (m Model) Init() tea.Cmd {
 	return nil
 }
 
-// VisibleSignalCount returns the number of signals that can be displayed
-func (m Model) VisibleSignalCount() int {
+// ContentHeight returns the number of terminal lines available for signal rows
+func (m Model) ContentHeight() int {
 	// Reserve lines for: title, timeline, separator, status bar
-	available := m.Height - 4
-
-	// Each signal takes 1 line
+	available := m.Height - m.HeaderHeight() - m.FooterHeight()
 	if available < 1 {
 		return 1
 	}
 	return available
 }
 
+// SignalRowHeight returns the number of terminal lines used by a signal
+func (m Model) SignalRowHeight(globalIdx int) int {
+	if !m.TallMode || globalIdx < 0 || globalIdx >= len(m.Signals) {
+		return 1
+	}
+	return render.TallRowCount(m.Signals[globalIdx])
+}
+
+// HeaderHeight returns the number of lines above the signal rows
+func (m Model) HeaderHeight() int {
+	if m.OverviewVisible {
+		return HeaderLines + 1
+	}
+	return HeaderLines
+}
+
+// OverviewTimeAt returns the time at a column of the overview bar
+func (m Model) OverviewTimeAt(col int) uint64 {
+	width := m.WaveformWidth()
+	col = max(0, min(col, width-1))
+	return uint64((float64(col) + 0.5) * float64(m.VCD.EndTime) / float64(width))
+}
+
+// RowHeight returns the number of terminal lines used by a display row
+func (m Model) RowHeight(row Row) int {
+	if row.Kind != RowSignal {
+		return 1
+	}
+	return m.SignalRowHeight(row.Signal)
+}
+
+// VisibleRowCount returns the number of display rows that can be shown
+// starting at the current scroll offset
+func (m Model) VisibleRowCount() int {
+	available := m.ContentHeight()
+	if !m.TallMode {
+		// Each row takes 1 line
+		return available
+	}
+	return m.rowsFitting(m.DisplayRows(), m.SignalScrollOffset, available)
+}
+
+// rowsFitting counts how many display rows from offset fit into the given lines
+func (m Model) rowsFitting(rows []Row, offset, lines int) int {
+	count := 0
+	for i := offset; i < len(rows); i++ {
+		lines -= m.RowHeight(rows[i])
+		if lines < 0 {
+			break
+		}
+		count++
+	}
+	if count < 1 {
+		return 1
+	}
+	return count
+}
+
 // WaveformWidth returns the width available for waveform display
 func (m Model) WaveformWidth() int {
 	// Total width minus signal pane and separator
@@ -132,6 +253,41 @@ func (m Model) WaveformWidth() int {
 	return w
 }
 
+// WaveformLeft returns the terminal column where the waveform pane starts
+func (m Model) WaveformLeft() int {
+	// Signal pane followed by the "│" separator
+	return m.SignalPaneWidth + 1
+}
+
+// TimeAtColumn returns the time at the start of a waveform column
+func (m Model) TimeAtColumn(col int) uint64 {
+	width := m.WaveformWidth()
+	if col < 0 {
+		col = 0
+	}
+	if col >= width {
+		col = width - 1
+	}
+	timeRange := float64(m.TimeEnd - m.TimeStart)
+	return m.TimeStart + uint64(float64(col)*timeRange/float64(width))
+}
+
+// RowAtLine returns the display row index shown at a terminal line, or -1
+func (m Model) RowAtLine(y int) int {
+	line := y - m.HeaderHeight()
+	if line < 0 || line >= m.ContentHeight() {
+		return -1
+	}
+	rows := m.DisplayRows()
+	for i := m.SignalScrollOffset; i < len(rows); i++ {
+		line -= m.RowHeight(rows[i])
+		if line < 0 {
+			return i
+		}
+	}
+	return -1
+}
+
 // ScrollTimeLeft scrolls the time window left
 func (m *Model) ScrollTimeLeft(amount uint64) {
 	if m.TimeStart >= amount {
@@ -160,119 +316,61 @@ func (m *Model) ScrollTimeRight(amount uint64) {
 	}
 }
 
-// ZoomIn increases zoom level
-func (m *Model) ZoomIn() {
-	m.Zoom *= 2
-	m.recalculateTimeWindow()
-}
-
-// ZoomOut decreases zoom level
-func (m *Model) ZoomOut() {
-	m.Zoom /= 2
-	if m.Zoom < 0.125 {
-		m.Zoom = 0.125
+// MoveSignalUp moves selection up
+func (m *Model) MoveSignalUp() {
+	// 選択モード: 全信号、通常モード: 表示行内で移動
+	if pos := m.SelectedRowIndex(); pos > 0 {
+		m.SelectRow(pos - 1)
+		m.adjustSignalScroll()
 	}
-	m.recalculateTimeWindow()
 }
 
-// ResetZoom shows the entire waveform
-func (m *Model) ResetZoom() {
-	m.Zoom = 1.0
-	m.TimeStart = 0
-	m.TimeEnd = m.VCD.EndTime
-	m.recalculateTimeWindow()
+// MoveSignalDown moves selection down
+func (m *Model) MoveSignalDown() {
+	pos := m.SelectedRowIndex()
+	if pos >= 0 && pos < len(m.DisplayRows())-1 {
+		m.SelectRow(pos + 1)
+		m.adjustSignalScroll()
+	}
 }
 
-// recalculateTimeWindow recalculates time window based on zoom and cursor
-func (m *Model) recalculateTimeWindow() {
-	waveWidth := uint64(m.WaveformWidth())
-	visibleDuration := uint64(float64(waveWidth*m.TimePerChar) / m.Zoom)
-
-	// Center on cursor if visible, otherwise on current window center
-	center := m.CursorTime
-	if center < m.TimeStart || center > m.TimeEnd {
-		center = (m.TimeStart + m.TimeEnd) / 2
+// adjustSignalScroll adjusts scroll to keep the selected row visible
+func (m *Model) adjustSignalScroll() {
+	pos := m.SelectedRowIndex()
+	if pos < 0 {
+		return
 	}
 
-	halfDuration := visibleDuration / 2
-	if center > halfDuration {
-		m.TimeStart = center - halfDuration
-	} else {
-		m.TimeStart = 0
+	if pos < m.SignalScrollOffset {
+		m.SignalScrollOffset = pos
+		return
 	}
-
-	m.TimeEnd = m.TimeStart + visibleDuration
-	if m.TimeEnd > m.VCD.EndTime {
-		m.TimeEnd = m.VCD.EndTime
-		if m.VCD.EndTime > visibleDuration {
-			m.TimeStart = m.VCD.EndTime - visibleDuration
-		} else {
-			m.TimeStart = 0
-		}
+	if pos < m.SignalScrollOffset+m.VisibleRowCount() {
+		return
 	}
-}
 
-// MoveSignalUp moves selection up
-func (m *Model) MoveSignalUp() {
-	if m.SelectMode {
-		// 選択モード: 全信号内で移動
-		if m.SelectedSignal > 0 {
-			m.SelectedSignal--
-			m.adjustSignalScroll()
-		}
-	} else {
-		// 通常モード: 表示信号内で移動
-		indices := m.VisibleSignalIndices()
-		currentVisibleIdx := m.GlobalIndexToVisible(m.SelectedSignal)
-		if currentVisibleIdx > 0 {
-			m.SelectedSignal = indices[currentVisibleIdx-1]
-			m.adjustSignalScroll()
-		}
+	// Scroll down just far enough: the selected row at the bottom, with as
+	// many rows above it as fit
+	rows := m.DisplayRows()
+	lines := m.ContentHeight() - m.RowHeight(rows[pos])
+	offset := pos
+	for offset > 0 && m.RowHeight(rows[offset-1]) <= lines {
+		offset--
+		lines -= m.RowHeight(rows[offset])
 	}
+	m.SignalScrollOffset = offset
 }
 
-// MoveSignalDown moves selection down
-func (m *Model) MoveSignalDown() {
-	if m.SelectMode {
-		// 選択モード: 全信号内で移動
-		if m.SelectedSignal < len(m.Signals)-1 {
-			m.SelectedSignal++
-			m.adjustSignalScroll()
-		}
-	} else {
-		// 通常モード: 表示信号内で移動
-		indices := m.VisibleSignalIndices()
-		currentVisibleIdx := m.GlobalIndexToVisible(m.SelectedSignal)
-		if currentVisibleIdx >= 0 && currentVisibleIdx < len(indices)-1 {
-			m.SelectedSignal = indices[currentVisibleIdx+1]
-			m.adjustSignalScroll()
-		}
-	}
+// ToggleTallMode switches between single-line and multi-row waveforms
+func (m *Model) ToggleTallMode() {
+	m.TallMode = !m.TallMode
+	m.adjustSignalScroll()
 }
 
-// adjustSignalScroll adjusts scroll to keep selected signal visible
-func (m *Model) adjustSignalScroll() {
-	visibleCount := m.VisibleSignalCount()
-
-	if m.SelectMode {
-		// 選択モード: 全信号を対象にスクロール
-		if m.SelectedSignal < m.SignalScrollOffset {
-			m.SignalScrollOffset = m.SelectedSignal
-		} else if m.SelectedSignal >= m.SignalScrollOffset+visibleCount {
-			m.SignalScrollOffset = m.SelectedSignal - visibleCount + 1
-		}
-	} else {
-		// 通常モード: 表示信号リスト内での位置を計算
-		visibleIdx := m.GlobalIndexToVisible(m.SelectedSignal)
-		if visibleIdx < 0 {
-			return
-		}
-		if visibleIdx < m.SignalScrollOffset {
-			m.SignalScrollOffset = visibleIdx
-		} else if visibleIdx >= m.SignalScrollOffset+visibleCount {
-			m.SignalScrollOffset = visibleIdx - visibleCount + 1
-		}
-	}
+// ToggleOverview shows or hides the overview bar above the timeline
+func (m *Model) ToggleOverview() {
+	m.OverviewVisible = !m.OverviewVisible
+	m.adjustSignalScroll()
 }
 
 // GoToStart moves to time 0
@@ -298,32 +396,40 @@ func (m *Model) GoToEnd() {
 	}
 }
 
+// GoTo moves the cursor to a time ("1500", "1.2us") or a marker
+func (m *Model) GoTo(spec string) error {
+	t, err := m.ResolveTime(spec)
+	if err != nil {
+		return err
+	}
+	if t > m.VCD.EndTime {
+		return fmt.Errorf("%s is past the end of the dump", spec)
+	}
+	m.CursorTime = t
+	m.ensureCursorVisible()
+	return nil
+}
+
 // NextChange moves cursor to next value change of selected signal
 func (m *Model) NextChange() {
-	if m.SelectedSignal >= len(m.Signals) {
+	sig := m.SelectedSignalData()
+	if sig == nil {
 		return
 	}
-	sig := m.Signals[m.SelectedSignal]
-	for _, change := range sig.Changes {
-		if change.Time > m.CursorTime {
-			m.CursorTime = change.Time
-			m.ensureCursorVisible()
-			return
-		}
+	if change, ok := sig.NextChange(m.CursorTime); ok {
+		m.CursorTime = change.Time
+		m.ensureCursorVisible()
 	}
 }
 
 // PrevChange moves cursor to previous value change of selected signal
 func (m *Model) PrevChange() {
-	if m.SelectedSignal >= len(m.Signals) {
+	sig := m.SelectedSignalData()
+	if sig == nil {
 		return
 	}
-	sig := m.Signals[m.SelectedSignal]
 	var prevTime uint64 = 0
-	for _, change := range sig.Changes {
-		if change.Time >= m.CursorTime {
-			break
-		}
+	if change, ok := sig.PrevChange(m.CursorTime); ok {
 		prevTime = change.Time
 	}
 	m.CursorTime = prevTime
@@ -347,7 +453,7 @@ func (m *Model) ensureCursorVisible() {
 }
 
 // SelectedSignalData returns the currently selected signal data
-func (m Model) SelectedSignalData() *vcd.SignalData {
+func (m Model) SelectedSignalData() *waveform.SignalData {
 	if m.SelectedSignal >= 0 && m.SelectedSignal < len(m.Signals) {
 		return m.Signals[m.SelectedSignal]
 	}
@@ -372,59 +478,59 @@ func (m *Model) Search(query string) {
 
 	// Jump to first result
 	if len(m.SearchResult) > 0 {
-		m.SelectedSignal = m.SearchResult[0]
+		m.selectSignal(m.SearchResult[0])
 		m.adjustSignalScroll()
 	}
 }
 
-// VisibleSignalIndices returns indices of visible signals
-func (m *Model) VisibleSignalIndices() []int {
-	var indices []int
-	for i, visible := range m.SignalVisible {
-		if visible {
-			indices = append(indices, i)
-		}
-	}
-	return indices
-}
-
 // ToggleSignalVisibility toggles visibility of the selected signal
 func (m *Model) ToggleSignalVisibility() {
 	if m.SelectedSignal >= 0 && m.SelectedSignal < len(m.SignalVisible) {
+		m.changeVisibility()
 		m.SignalVisible[m.SelectedSignal] = !m.SignalVisible[m.SelectedSignal]
 	}
 }
 
 // SetAllSignalsVisible sets visibility for all signals
 func (m *Model) SetAllSignalsVisible(visible bool) {
+	m.changeVisibility()
 	for i := range m.SignalVisible {
 		m.SignalVisible[i] = visible
 	}
 }
 
-// VisibleIndexToGlobal converts a visible signal index to global index
-func (m *Model) VisibleIndexToGlobal(visibleIdx int) int {
-	indices := m.VisibleSignalIndices()
-	if visibleIdx >= 0 && visibleIdx < len(indices) {
-		return indices[visibleIdx]
-	}
-	return 0
-}
-
-// GlobalIndexToVisible converts a global signal index to visible index (-1 if not visible)
-func (m *Model) GlobalIndexToVisible(globalIdx int) int {
-	indices := m.VisibleSignalIndices()
-	for i, idx := range indices {
-		if idx == globalIdx {
-			return i
+// SetMatchingVisible shows or hides the signals whose full name or name
+// matches a glob pattern such as "top.u_dma.*", and returns how many matched
+func (m *Model) SetMatchingVisible(pattern string, visible bool) (int, error) {
+	count := 0
+	for i, sig := range m.Signals {
+		matched, err := path.Match(pattern, sig.Signal.FullName)
+		if err != nil {
+			return 0, fmt.Errorf("invalid pattern %q", pattern)
+		}
+		if !matched {
+			matched, _ = path.Match(pattern, sig.Signal.Name)
+		}
+		if matched {
+			if count == 0 {
+				m.changeVisibility()
+			}
+			m.SignalVisible[i] = visible
+			count++
 		}
 	}
-	return -1
+	if count == 0 {
+		return 0, fmt.Errorf("no signal matches %q", pattern)
+	}
+	m.ensureSelection()
+	m.adjustSignalScroll()
+	return count, nil
 }
 
 // EnterSelectMode enters signal selection mode
 func (m *Model) EnterSelectMode() {
 	m.SelectMode = true
+	m.invalidateRows()
 	m.SignalScrollOffset = 0
 	m.adjustSignalScroll()
 }
@@ -432,13 +538,9 @@ func (m *Model) EnterSelectMode() {
 // ExitSelectMode exits signal selection mode
 func (m *Model) ExitSelectMode() {
 	m.SelectMode = false
-	// 現在選択中の信号が非表示の場合、最初の表示信号を選択
-	if !m.SignalVisible[m.SelectedSignal] {
-		indices := m.VisibleSignalIndices()
-		if len(indices) > 0 {
-			m.SelectedSignal = indices[0]
-		}
-	}
+	m.invalidateRows()
+	// 現在選択中の行が非表示の場合、最初の行を選択
+	m.ensureSelection()
 	m.SignalScrollOffset = 0
 	m.adjustSignalScroll()
 }
@@ -452,30 +554,87 @@ func (m *Model) ToggleSelectMode() {
 	}
 }
 
-
-// DisplaySignalCount returns the number of signals to display (depends on mode)
-func (m *Model) DisplaySignalCount() int {
-	if m.SelectMode {
-		return len(m.Signals)
-	}
-	return len(m.VisibleSignalIndices())
-}
-
 // ViewState holds the current view state for restoration after reload
 type ViewState struct {
 	CursorTime         uint64
-	SelectedSignal     int
+	SelectedRow        int // 選択行（SelectedNameで見つからない場合に使用）
 	TimeStart          uint64
 	TimeEnd            uint64
 	Zoom               float64
 	SignalScrollOffset int
 	SelectMode         bool
-	SignalVisible      []bool   // 信号可視性を保持
-	SignalNames        []string // 名前でマッチング用
+	SignalVisible      []bool       // 信号可視性を保持
+	SignalNames        []string     // 名前でマッチング用
+	ExpandedBuses      []string     // ビット展開中のバス
+	VirtualBuses       []VirtualBus // 仮想バス定義
+	SelectedName       string       // 選択信号（名前で復元、空ならインデックス）
+	HideUnlisted       bool         // true: SignalNamesにない信号は非表示（セッション読込用）
+	Layout             []*Entry     // 表示順・区切り線・グループ（nilなら現在の並びを維持）
+	Markers            []Marker
+	Radix              map[string]render.Radix
+	Colors             map[string]string
+	TallMode           bool
+}
+
+// CaptureViewState snapshots the current view state for later restoration
+func (m Model) CaptureViewState() ViewState {
+	return ViewState{
+		CursorTime:         m.CursorTime,
+		SelectedRow:        m.SelectedRowIndex(),
+		TimeStart:          m.TimeStart,
+		TimeEnd:            m.TimeEnd,
+		Zoom:               m.Zoom,
+		SignalScrollOffset: m.SignalScrollOffset,
+		SelectMode:         m.SelectMode,
+		SignalVisible:      append([]bool{}, m.SignalVisible...),
+		SignalNames:        m.ExtractSignalNames(),
+		ExpandedBuses:      m.copyExpandedBuses(),
+		VirtualBuses:       append([]VirtualBus{}, m.VirtualBuses...),
+		Layout:             copyLayout(m.Layout, nil),
+		SelectedName:       m.selectedName(),
+		Markers:            append([]Marker{}, m.Markers...),
+		Radix:              m.copyRadix(),
+		Colors:             m.copyColors(),
+		TallMode:           m.TallMode,
+	}
+}
+
+// selectedName returns the full name of the selected signal, or ""
+func (m Model) selectedName() string {
+	if sig := m.SelectedSignalData(); sig != nil {
+		return sig.Signal.FullName
+	}
+	return ""
+}
+
+// Reload switches in place to new VCD data read from filename. The view
+// state is carried over to the new signals; everything else, such as the
+// mode and any input being typed, stays as it is.
+func (m *Model) Reload(vcdFile *waveform.VCDFile, filename string) {
+	state := m.CaptureViewState()
+	m.VCD = vcdFile
+	m.Filename = filename
+	m.RestoreViewState(state)
+
+	m.LastReloadTime = time.Now()
+	m.ReloadError = ""
+	m.WatchError = ""
 }
 
 // RestoreViewState restores the view state after VCD reload
 func (m *Model) RestoreViewState(state ViewState) {
+	// 派生信号（ビット展開・仮想バス）を再構築
+	m.ExpandedBuses = make(map[string]bool, len(state.ExpandedBuses))
+	for _, name := range state.ExpandedBuses {
+		m.ExpandedBuses[name] = true
+	}
+	m.VirtualBuses = append([]VirtualBus{}, state.VirtualBuses...)
+	if state.Layout != nil {
+		m.Layout = copyLayout(state.Layout, nil)
+		m.selectedEntry = nil
+	}
+	m.rebuildSignals()
+
 	// カーソル位置復元（範囲チェック）
 	if state.CursorTime <= m.VCD.EndTime {
 		m.CursorTime = state.CursorTime
@@ -483,15 +642,8 @@ func (m *Model) RestoreViewState(state ViewState) {
 		m.CursorTime = m.VCD.EndTime
 	}
 
-	// 選択信号復元（範囲チェック）
-	if state.SelectedSignal < len(m.Signals) {
-		m.SelectedSignal = state.SelectedSignal
-	} else if len(m.Signals) > 0 {
-		m.SelectedSignal = 0
-	}
-
 	// 時間ウィンドウ復元（範囲チェック）
-	if state.TimeStart <= m.VCD.EndTime && state.TimeEnd <= m.VCD.EndTime {
+	if state.TimeStart < state.TimeEnd && state.TimeEnd <= m.VCD.EndTime {
 		m.TimeStart = state.TimeStart
 		m.TimeEnd = state.TimeEnd
 	} else {
@@ -499,12 +651,21 @@ func (m *Model) RestoreViewState(state ViewState) {
 		m.TimeEnd = m.VCD.EndTime
 	}
 
-	// ズームレベル復元
-	m.Zoom = state.Zoom
+	// ズームレベルは時間ウィンドウから算出
+	m.Zoom = m.zoomLevel()
 
-	// スクロールオフセット復元
-	m.SignalScrollOffset = state.SignalScrollOffset
-	m.adjustSignalScroll()
+	// マーカー・基数・色・表示モード復元
+	m.Markers = append([]Marker{}, state.Markers...)
+	m.sortMarkers()
+	m.Radix = make(map[string]render.Radix, len(state.Radix))
+	for name, r := range state.Radix {
+		m.Radix[name] = r
+	}
+	m.Colors = make(map[string]string, len(state.Colors))
+	for name, c := range state.Colors {
+		m.Colors[name] = c
+	}
+	m.TallMode = state.TallMode
 
 	// 選択モード復元
 	m.SelectMode = state.SelectMode
@@ -524,9 +685,20 @@ func (m *Model) RestoreViewState(state ViewState) {
 			m.SignalVisible[i] = visible
 		} else {
 			// 新規信号はデフォルトで表示
-			m.SignalVisible[i] = true
+			m.SignalVisible[i] = !state.HideUnlisted
 		}
 	}
+	m.invalidateRows()
+
+	// 選択行を名前で復元（見つからなければ行位置、それも無理なら先頭行）
+	if state.SelectedName == "" || !m.selectByName(state.SelectedName) {
+		m.SelectRow(state.SelectedRow)
+	}
+	m.ensureSelection()
+
+	// スクロールオフセット復元
+	m.SignalScrollOffset = state.SignalScrollOffset
+	m.adjustSignalScroll()
 }
 
 // ExtractSignalNames extracts signal names for state preservation
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+// testVCD returns a dump of n signals, every third one a bus
+func testVCD(n int) *waveform.VCDFile {
+	v := waveform.NewVCDFile()
+	v.EndTime = 100
+	for i := range n {
+		width := 1
+		if i%3 == 0 {
+			width = 8
+		}
+		id := fmt.Sprintf("s%d", i)
+		name := fmt.Sprintf("sig%03d", i)
+		v.Signals[id] = &waveform.SignalData{
+			Signal:  waveform.Signal{ID: id, Name: name, Width: width, Scope: "top", FullName: "top." + name},
+			Changes: []waveform.ValueChange{},
+		}
+	}
+	return v
+}
+
+// withDividersAndGroups adds dividers and groups, some collapsed, between
+// the signals of a model
+func withDividersAndGroups(rng *rand.Rand, m *Model) {
+	for i := 0; i < len(m.DisplayRows()); i += 1 + rng.Intn(8) {
+		m.SelectRow(i)
+		if rng.Intn(2) == 0 {
+			m.InsertDivider(fmt.Sprintf("div%d", i))
+			continue
+		}
+		if err := m.CreateGroup(""); err != nil {
+			panic(err)
+		}
+		pos := m.SelectedRowIndex()
+
+		// Pull the rows below into the group, then maybe collapse it
+		for members := 1; members <= rng.Intn(4); members++ {
+			m.SelectRow(pos + 1 + members)
+			m.MoveRowUp()
+		}
+		if rng.Intn(3) == 0 {
+			m.SelectRow(pos)
+			m.ToggleGroupCollapse()
+		}
+	}
+	m.SelectRow(0)
+	m.SignalScrollOffset = 0
+}
+
+func TestAdjustSignalScroll(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, layout := range []bool{false, true} {
+		for _, tall := range []bool{false, true} {
+			for _, height := range []int{8, 11, 24, 50} {
+				m := NewModel(testVCD(200), "test.vcd")
+				m.TallMode = tall
+				m.Height = height
+				if layout {
+					withDividersAndGroups(rng, &m)
+				}
+				rows := len(m.DisplayRows())
+
+				for range 500 {
+					// Mostly single steps, sometimes jumps, over signals,
+					// dividers and group headers alike
+					next := m.SelectedRowIndex() + rng.Intn(3) - 1
+					if rng.Intn(10) == 0 {
+						next = rng.Intn(rows)
+					}
+					m.SelectRow(min(max(next, 0), rows-1))
+					pos := m.SelectedRowIndex()
+
+					// Scrolling one row at a time, as before
+					want := m.SignalScrollOffset
+					if pos < want {
+						want = pos
+					} else {
+						ref := m
+						for pos >= ref.SignalScrollOffset+ref.VisibleRowCount() {
+							ref.SignalScrollOffset++
+						}
+						want = ref.SignalScrollOffset
+					}
+
+					m.adjustSignalScroll()
+					if m.SignalScrollOffset != want {
+						t.Fatalf("layout %v, tall %v, height %d, row %d: offset %d, want %d",
+							layout, tall, height, pos, m.SignalScrollOffset, want)
+					}
+					if pos < m.SignalScrollOffset || pos >= m.SignalScrollOffset+m.VisibleRowCount() {
+						t.Fatalf("layout %v, tall %v, height %d: row %d not shown from offset %d",
+							layout, tall, height, pos, m.SignalScrollOffset)
+					}
+				}
+			}
+		}
+	}
+}
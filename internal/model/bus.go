@@ -0,0 +1,214 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+// VirtualBus is a user-defined bus assembled from 1-bit signals
+type VirtualBus struct {
+	Name string   // Display and full name of the bus
+	Bits []string // Full names of the member signals, MSB first
+}
+
+// rebuildSignals recomputes the signal list from the VCD data plus expanded
+// bus bits and virtual buses. Visibility and selection are kept by name and
+// the layout is brought in line with the new list.
+func (m *Model) rebuildSignals() {
+	visibleByName := make(map[string]bool, len(m.Signals))
+	for i, sig := range m.Signals {
+		if i < len(m.SignalVisible) {
+			visibleByName[sig.Signal.FullName] = m.SignalVisible[i]
+		}
+	}
+	selectedName := ""
+	if sel := m.SelectedSignalData(); sel != nil {
+		selectedName = sel.Signal.FullName
+	}
+
+	base := m.VCD.GetSignalList()
+
+	signals := make([]*waveform.SignalData, 0, len(base))
+	byName := make(map[string]*waveform.SignalData, len(base))
+	m.bitParent = make(map[string]string)
+
+	for _, sig := range base {
+		signals = append(signals, sig)
+		byName[sig.Signal.FullName] = sig
+
+		// Insert one child row per bit, MSB first, below an expanded bus
+		if sig.Signal.Width > 1 && m.ExpandedBuses[sig.Signal.FullName] {
+			for bit := sig.Signal.Width - 1; bit >= 0; bit-- {
+				child := waveform.ExtractBit(sig, bit)
+				signals = append(signals, child)
+				byName[child.Signal.FullName] = child
+				m.bitParent[child.Signal.FullName] = sig.Signal.FullName
+			}
+		}
+	}
+
+	for _, vb := range m.VirtualBuses {
+		bits := make([]*waveform.SignalData, 0, len(vb.Bits))
+		for _, name := range vb.Bits {
+			if sig, ok := byName[name]; ok {
+				bits = append(bits, sig)
+			}
+		}
+		// Drop buses whose members disappeared from the dump
+		if len(bits) != len(vb.Bits) || len(bits) == 0 {
+			continue
+		}
+		signals = append(signals, waveform.CombineBits(vb.Name, bits))
+	}
+
+	m.Signals = signals
+	m.SignalVisible = make([]bool, len(signals))
+	m.derivedFrom = changeCounts(signals)
+	m.signalIndex = make(map[string]int, len(signals))
+	if m.selectedEntry == nil {
+		m.SelectedSignal = 0
+	}
+	for i, sig := range signals {
+		m.signalIndex[sig.Signal.FullName] = i
+		if visible, found := visibleByName[sig.Signal.FullName]; found {
+			m.SignalVisible[i] = visible
+		} else {
+			// 新規信号はデフォルトで表示
+			m.SignalVisible[i] = true
+		}
+		if sig.Signal.FullName == selectedName {
+			m.SelectedSignal = i
+		}
+	}
+	m.SearchResult = nil
+	m.syncLayout()
+}
+
+// IsBusBit reports whether the signal is a bit row derived from an expanded bus
+func (m Model) IsBusBit(globalIdx int) bool {
+	if globalIdx < 0 || globalIdx >= len(m.Signals) {
+		return false
+	}
+	_, ok := m.bitParent[m.Signals[globalIdx].Signal.FullName]
+	return ok
+}
+
+// virtualBusIndex returns the index in VirtualBuses for a name, or -1
+func (m Model) virtualBusIndex(name string) int {
+	for i, vb := range m.VirtualBuses {
+		if vb.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ToggleBusExpansion expands the selected bus into bits or collapses it again.
+// On a bit row the parent bus is collapsed; on a virtual bus the bus is dissolved.
+func (m *Model) ToggleBusExpansion() {
+	sig := m.SelectedSignalData()
+	if sig == nil {
+		return
+	}
+	name := sig.Signal.FullName
+
+	if parent, ok := m.bitParent[name]; ok {
+		delete(m.ExpandedBuses, parent)
+		m.selectByName(parent)
+	} else if idx := m.virtualBusIndex(name); idx >= 0 {
+		m.VirtualBuses = append(m.VirtualBuses[:idx:idx], m.VirtualBuses[idx+1:]...)
+		m.StatusMessage = fmt.Sprintf("Removed virtual bus %s", name)
+	} else if sig.Signal.Width > 1 {
+		if m.ExpandedBuses == nil {
+			m.ExpandedBuses = make(map[string]bool)
+		}
+		if m.ExpandedBuses[name] {
+			delete(m.ExpandedBuses, name)
+		} else {
+			m.ExpandedBuses[name] = true
+		}
+	} else {
+		return
+	}
+
+	m.rebuildSignals()
+	m.adjustSignalScroll()
+}
+
+// TogglePick adds or removes the selected 1-bit signal from the virtual bus picks
+func (m *Model) TogglePick() {
+	sig := m.SelectedSignalData()
+	if sig == nil || sig.Signal.Width != 1 {
+		return
+	}
+	name := sig.Signal.FullName
+	for i, picked := range m.PickedSignals {
+		if picked == name {
+			m.PickedSignals = append(m.PickedSignals[:i:i], m.PickedSignals[i+1:]...)
+			return
+		}
+	}
+	m.PickedSignals = append(m.PickedSignals, name)
+}
+
+// IsPicked reports whether a signal is picked for a virtual bus
+func (m Model) IsPicked(globalIdx int) bool {
+	if globalIdx < 0 || globalIdx >= len(m.Signals) {
+		return false
+	}
+	for _, picked := range m.PickedSignals {
+		if picked == m.Signals[globalIdx].Signal.FullName {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateVirtualBus combines the picked signals into a virtual bus.
+// The first picked signal becomes the MSB.
+func (m *Model) CreateVirtualBus(name string) error {
+	if len(m.PickedSignals) < 2 {
+		return fmt.Errorf("pick at least two 1-bit signals")
+	}
+	if name == "" {
+		name = fmt.Sprintf("vbus%d", len(m.VirtualBuses))
+	}
+	for _, sig := range m.Signals {
+		if sig.Signal.FullName == name {
+			return fmt.Errorf("signal %s already exists", name)
+		}
+	}
+
+	m.VirtualBuses = append(m.VirtualBuses, VirtualBus{
+		Name: name,
+		Bits: append([]string{}, m.PickedSignals...),
+	})
+	m.PickedSignals = nil
+	m.rebuildSignals()
+	m.selectByName(name)
+	m.adjustSignalScroll()
+	return nil
+}
+
+// selectByName selects the signal with the given full name, if present
+func (m *Model) selectByName(name string) bool {
+	idx, ok := m.signalIndex[name]
+	if ok {
+		m.selectSignal(idx)
+	}
+	return ok
+}
+
+// copyExpandedBuses returns the names of expanded buses for state preservation
+func (m Model) copyExpandedBuses() []string {
+	names := make([]string, 0, len(m.ExpandedBuses))
+	for name, expanded := range m.ExpandedBuses {
+		if expanded {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+// busVCD returns a dump with three 1-bit signals and a 4-bit bus
+func busVCD() *waveform.VCDFile {
+	v := waveform.NewVCDFile()
+	v.EndTime = 30
+	add := func(id, name string, width int, changes ...waveform.ValueChange) {
+		v.Signals[id] = &waveform.SignalData{
+			Signal:  waveform.Signal{ID: id, Name: name, Width: width, Scope: "top", FullName: "top." + name},
+			Changes: changes,
+		}
+	}
+	add("!", "a", 1, waveform.ValueChange{Time: 0, Value: "0"}, waveform.ValueChange{Time: 10, Value: "1"})
+	add("#", "b", 1, waveform.ValueChange{Time: 0, Value: "1"}, waveform.ValueChange{Time: 20, Value: "0"})
+	add("$", "c", 1, waveform.ValueChange{Time: 0, Value: "x"})
+	add("%", "data", 4, waveform.ValueChange{Time: 0, Value: "101"})
+	return v
+}
+
+// names returns the full names of the model's signals in order
+func names(m Model) string {
+	var s []string
+	for _, sig := range m.Signals {
+		s = append(s, sig.Signal.FullName)
+	}
+	return fmt.Sprint(s)
+}
+
+func TestToggleBusExpansion(t *testing.T) {
+	m := NewModel(busVCD(), "test.vcd")
+	m.selectByName("top.data")
+	m.ToggleBusExpansion()
+	want := "[top.a top.b top.c top.data top.data[3] top.data[2] top.data[1] top.data[0]]"
+	if got := names(m); got != want {
+		t.Fatalf("expanded: %s, want %s", got, want)
+	}
+	if !m.IsBusBit(4) || m.IsBusBit(3) {
+		t.Error("bit rows not marked")
+	}
+
+	// Collapsing from a bit row selects the bus again
+	m.selectByName("top.data[1]")
+	m.ToggleBusExpansion()
+	if got := names(m); got != "[top.a top.b top.c top.data]" {
+		t.Errorf("collapsed: %s", got)
+	}
+	if sel := m.SelectedSignalData(); sel.Signal.FullName != "top.data" {
+		t.Errorf("selected %s after collapsing", sel.Signal.FullName)
+	}
+}
+
+func TestVirtualBus(t *testing.T) {
+	tests := []struct {
+		name    string
+		picks   []string // Signals selected and picked in turn
+		want    string   // Changes of the virtual bus, "" if not created
+		wantErr bool
+	}{
+		{"MSB first", []string{"top.a", "top.b"}, "[{0 01} {10 11} {20 10}]", false},
+		{"picked twice is unpicked", []string{"top.a", "top.b", "top.c", "top.c"}, "[{0 01} {10 11} {20 10}]", false},
+		{"buses are not picked", []string{"top.a", "top.data", "top.c"}, "[{0 0x} {10 1x}]", false},
+		{"too few", []string{"top.a", "top.b", "top.b"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel(busVCD(), "test.vcd")
+			for _, name := range tt.picks {
+				m.selectByName(name)
+				m.TogglePick()
+			}
+			err := m.CreateVirtualBus("vb")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if len(m.PickedSignals) != 0 {
+				t.Errorf("picks %v left over", m.PickedSignals)
+			}
+			sel := m.SelectedSignalData()
+			if sel.Signal.FullName != "vb" {
+				t.Fatalf("selected %s, want the new bus", sel.Signal.FullName)
+			}
+			if got := fmt.Sprint(sel.Changes); got != tt.want {
+				t.Errorf("changes %s, want %s", got, tt.want)
+			}
+
+			// The name is taken now
+			m.PickedSignals = []string{"top.a", "top.b"}
+			if err := m.CreateVirtualBus("vb"); err == nil {
+				t.Error("created a second bus with the same name")
+			}
+		})
+	}
+}
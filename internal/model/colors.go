@@ -0,0 +1,86 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// colorNames maps color names accepted by SetSignalColor to ANSI colors
+var colorNames = map[string]string{
+	"black":   "0",
+	"red":     "1",
+	"green":   "2",
+	"yellow":  "3",
+	"blue":    "4",
+	"magenta": "5",
+	"cyan":    "6",
+	"white":   "7",
+	"gray":    "8",
+	"grey":    "8",
+	"orange":  "208",
+	"purple":  "93",
+	"pink":    "205",
+}
+
+// hexColor matches a "#rrggbb" true color
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ParseColor normalizes a color name, ANSI number (0-255) or "#rrggbb"
+func ParseColor(spec string) (string, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if c, ok := colorNames[spec]; ok {
+		return c, nil
+	}
+	if n, err := strconv.Atoi(spec); err == nil && n >= 0 && n <= 255 {
+		return spec, nil
+	}
+	if hexColor.MatchString(spec) {
+		return spec, nil
+	}
+	return "", fmt.Errorf("invalid color %q (use a name, 0-255 or #rrggbb)", spec)
+}
+
+// SignalColor returns the color override of a signal, or "" for the theme color
+func (m Model) SignalColor(globalIdx int) string {
+	if globalIdx < 0 || globalIdx >= len(m.Signals) {
+		return ""
+	}
+	return m.Colors[m.Signals[globalIdx].Signal.FullName]
+}
+
+// SetSignalColor sets the color of the selected signal; "" or "none"
+// returns it to the theme color
+func (m *Model) SetSignalColor(spec string) error {
+	sig := m.SelectedSignalData()
+	if sig == nil {
+		return fmt.Errorf("no signal selected")
+	}
+	name := sig.Signal.FullName
+
+	if spec == "" || strings.EqualFold(spec, "none") {
+		delete(m.Colors, name)
+		m.StatusMessage = fmt.Sprintf("%s: theme color", sig.Signal.Name)
+		return nil
+	}
+	color, err := ParseColor(spec)
+	if err != nil {
+		return err
+	}
+	if m.Colors == nil {
+		m.Colors = make(map[string]string)
+	}
+	m.Colors[name] = color
+	m.StatusMessage = fmt.Sprintf("%s: color %s", sig.Signal.Name, color)
+	return nil
+}
+
+// copyColors returns a copy of the per-signal color overrides
+func (m Model) copyColors() map[string]string {
+	colors := make(map[string]string, len(m.Colors))
+	for name, c := range m.Colors {
+		colors[name] = c
+	}
+	return colors
+}
@@ -0,0 +1,56 @@
+package model
+
+import "testing"
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{"red", "1", false},
+		{" Orange ", "208", false},
+		{"grey", "8", false},
+		{"0", "0", false},
+		{"255", "255", false},
+		{"#FF8800", "#ff8800", false},
+		{"256", "", true},
+		{"-1", "", true},
+		{"#ff88", "", true},
+		{"ff8800", "", true},
+		{"chartreuse", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseColor(tt.spec)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseColor(%q) = %q, %v, want %q", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+func TestSetSignalColor(t *testing.T) {
+	m := NewModel(busVCD(), "test.vcd")
+	m.selectByName("top.b")
+	if err := m.SetSignalColor("blue"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.SignalColor(m.SelectedSignal); got != "4" {
+		t.Errorf("color %q, want 4", got)
+	}
+	if got := m.SignalColor(0); got != "" {
+		t.Errorf("other signal colored %q", got)
+	}
+	if err := m.SetSignalColor("nope"); err == nil || m.SignalColor(m.SelectedSignal) != "4" {
+		t.Errorf("invalid color: error %v, color %q", err, m.SignalColor(m.SelectedSignal))
+	}
+
+	// Copies don't share the colors
+	saved := m.copyColors()
+	if err := m.SetSignalColor("none"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.SignalColor(m.SelectedSignal); got != "" || saved["top.b"] != "4" {
+		t.Errorf("color %q after reset, saved %q", got, saved["top.b"])
+	}
+}
@@ -0,0 +1,158 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxCommandHistory bounds the number of remembered command lines
+const maxCommandHistory = 100
+
+// CommandLine holds the state of the ":" command line
+type CommandLine struct {
+	Input   string
+	History []string // Executed command lines, oldest first
+
+	historyPos     int      // Entry shown while browsing; len(History) = new input
+	completions    []string // Candidates cycled by repeated tab presses
+	completionPos  int
+	completionBase string // Input before the word being completed
+}
+
+// Start clears the input for a new command
+func (c *CommandLine) Start() {
+	c.Input = ""
+	c.historyPos = len(c.History)
+	c.completions = nil
+}
+
+// Type appends typed text to the input
+func (c *CommandLine) Type(s string) {
+	c.Input += s
+	c.completions = nil
+}
+
+// Backspace deletes the last character; false if the input was already empty
+func (c *CommandLine) Backspace() bool {
+	if c.Input == "" {
+		return false
+	}
+	runes := []rune(c.Input)
+	c.Input = string(runes[:len(runes)-1])
+	c.completions = nil
+	return true
+}
+
+// Remember adds an executed command line to the history
+func (c *CommandLine) Remember(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || len(c.History) > 0 && c.History[len(c.History)-1] == line {
+		return
+	}
+	c.History = append(c.History, line)
+	if len(c.History) > maxCommandHistory {
+		c.History = c.History[len(c.History)-maxCommandHistory:]
+	}
+}
+
+// HistoryPrev shows the previous command line in the history
+func (c *CommandLine) HistoryPrev() {
+	if c.historyPos > 0 {
+		c.historyPos--
+		c.Input = c.History[c.historyPos]
+		c.completions = nil
+	}
+}
+
+// HistoryNext shows the next command line, or an empty input past the newest
+func (c *CommandLine) HistoryNext() {
+	if c.historyPos >= len(c.History) {
+		return
+	}
+	c.historyPos++
+	c.Input = ""
+	if c.historyPos < len(c.History) {
+		c.Input = c.History[c.historyPos]
+	}
+	c.completions = nil
+}
+
+// Complete completes the last word of the input. candidates receives the
+// words before it and the partial word, and returns the possible words.
+// A unique candidate is completed with a trailing space; otherwise the
+// common prefix is filled in and further presses cycle the candidates.
+func (c *CommandLine) Complete(candidates func(words []string, partial string) []string) {
+	if len(c.completions) > 0 {
+		c.completionPos = (c.completionPos + 1) % len(c.completions)
+		c.Input = c.completionBase + c.completions[c.completionPos]
+		return
+	}
+
+	base, partial := c.Input, ""
+	if i := strings.LastIndex(c.Input, " "); i >= 0 {
+		base, partial = c.Input[:i+1], c.Input[i+1:]
+	} else {
+		base, partial = "", c.Input
+	}
+
+	var matches []string
+	for _, cand := range candidates(strings.Fields(base), partial) {
+		if strings.HasPrefix(cand, partial) {
+			matches = append(matches, cand)
+		}
+	}
+	sort.Strings(matches)
+
+	switch {
+	case len(matches) == 0:
+		return
+	case len(matches) == 1:
+		c.Input = base + matches[0]
+		// Scope prefixes ("top.u_dma.") continue with the next level
+		if !strings.HasSuffix(matches[0], ".") {
+			c.Input += " "
+		}
+	default:
+		if prefix := commonPrefix(matches); len(prefix) > len(partial) {
+			c.Input = base + prefix
+			return
+		}
+		c.completions = matches
+		c.completionPos = 0
+		c.completionBase = base
+		c.Input = base + matches[0]
+	}
+}
+
+// SignalNameCandidates returns the completions of a partial signal name,
+// one scope level at a time: "top.u" yields "top.u_dma." rather than every
+// signal below it
+func (m Model) SignalNameCandidates(partial string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, sig := range m.Signals {
+		name := sig.Signal.FullName
+		if !strings.HasPrefix(name, partial) {
+			continue
+		}
+		if i := strings.Index(name[len(partial):], "."); i >= 0 {
+			name = name[:len(partial)+i+1]
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// commonPrefix returns the longest prefix shared by all strings
+func commonPrefix(strs []string) string {
+	prefix := strs[0]
+	for _, s := range strs[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
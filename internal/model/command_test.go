@@ -0,0 +1,99 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommandLineComplete(t *testing.T) {
+	words := []string{"goto", "marker", "markers", "zoom"}
+	signals := []string{"top.clk", "top.u_dma.", "top.u_rx."}
+	candidates := func(before []string, partial string) []string {
+		if len(before) == 0 {
+			return words
+		}
+		return signals
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		tabs  int
+		want  string
+	}{
+		{"unique", "go", 1, "goto "},
+		{"common prefix", "ma", 1, "marker"},
+		{"cycle after the prefix", "marker", 1, "marker"},
+		{"cycle on", "marker", 2, "markers"},
+		{"cycle wraps", "marker", 3, "marker"},
+		{"no match", "x", 1, "x"},
+		{"scope continues", "show top.u_d", 1, "show top.u_dma."},
+		{"ambiguous scope", "show top.u", 1, "show top.u_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c CommandLine
+			c.Start()
+			c.Type(tt.input)
+			for range tt.tabs {
+				c.Complete(candidates)
+			}
+			if c.Input != tt.want {
+				t.Errorf("input %q, want %q", c.Input, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandLineHistory(t *testing.T) {
+	var c CommandLine
+	for _, line := range []string{"goto 10", " goto 10 ", "", "zoom 2"} {
+		c.Remember(line)
+	}
+	if want := []string{"goto 10", "zoom 2"}; !reflect.DeepEqual(c.History, want) {
+		t.Fatalf("history %v, want %v", c.History, want)
+	}
+
+	c.Start()
+	c.Type("ma")
+	steps := []struct {
+		prev bool
+		want string
+	}{
+		{true, "zoom 2"},
+		{true, "goto 10"},
+		{true, "goto 10"}, // Oldest stays
+		{false, "zoom 2"},
+		{false, ""}, // Past the newest
+		{false, ""},
+	}
+	for i, step := range steps {
+		if step.prev {
+			c.HistoryPrev()
+		} else {
+			c.HistoryNext()
+		}
+		if c.Input != step.want {
+			t.Errorf("step %d: input %q, want %q", i, c.Input, step.want)
+		}
+	}
+
+	for i := range maxCommandHistory + 10 {
+		c.Remember(string(rune('a' + i%26)))
+	}
+	if len(c.History) != maxCommandHistory {
+		t.Errorf("%d lines remembered, want %d", len(c.History), maxCommandHistory)
+	}
+}
+
+func TestCommandLineBackspace(t *testing.T) {
+	var c CommandLine
+	c.Type("µs")
+	if !c.Backspace() || c.Input != "µ" {
+		t.Errorf("input %q after backspace, want µ", c.Input)
+	}
+	c.Backspace()
+	if c.Backspace() {
+		t.Error("backspace in an empty input")
+	}
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DumpFile is a dump in the directory watched with --dir
+type DumpFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// dumpSuffixes are the file name endings of waveform dumps, plain or compressed
+var dumpSuffixes = []string{".vcd", ".vcd.gz", ".vcd.zst", ".vcd.xz"}
+
+// IsDumpFile reports whether a file name looks like a waveform dump
+func IsDumpFile(name string) bool {
+	name = strings.ToLower(name)
+	for _, suffix := range dumpSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListDumps returns the dumps in a directory, newest first
+func ListDumps(dir string) ([]DumpFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var dumps []DumpFile
+	for _, entry := range entries {
+		if entry.IsDir() || !IsDumpFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Removed while listing
+			continue
+		}
+		dumps = append(dumps, DumpFile{
+			Path:    filepath.Join(dir, entry.Name()),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	sort.Slice(dumps, func(i, j int) bool {
+		if !dumps[i].ModTime.Equal(dumps[j].ModTime) {
+			return dumps[i].ModTime.After(dumps[j].ModTime)
+		}
+		return dumps[i].Path > dumps[j].Path
+	})
+	return dumps, nil
+}
+
+// RefreshDumps lists the watched directory again
+func (m *Model) RefreshDumps() error {
+	dumps, err := ListDumps(m.DumpDir)
+	if err != nil {
+		return err
+	}
+	m.Dumps = dumps
+	m.PickerPos = max(0, min(m.PickerPos, len(dumps)-1))
+	return nil
+}
+
+// currentDump returns the index of the open file in Dumps, or -1
+func (m Model) currentDump() int {
+	for i, d := range m.Dumps {
+		if d.Path == m.Filename {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsCurrentDump reports whether a dump of the list is the open file
+func (m Model) IsCurrentDump(i int) bool {
+	return i == m.currentDump()
+}
+
+// NewerDump returns the dump that was written next after the open one, or
+// the newest dump if the open one is no longer listed
+func (m Model) NewerDump() (DumpFile, error) {
+	if m.DumpDir == "" {
+		return DumpFile{}, fmt.Errorf("not watching a directory (start with --dir)")
+	}
+	i := m.currentDump()
+	switch {
+	case len(m.Dumps) == 0:
+		return DumpFile{}, fmt.Errorf("no dumps in %s", m.DumpDir)
+	case i == 0:
+		return DumpFile{}, fmt.Errorf("already showing the newest dump")
+	case i < 0:
+		return m.Dumps[0], nil
+	}
+	return m.Dumps[i-1], nil
+}
+
+// OpenPicker shows the dumps of the watched directory, starting at the open one
+func (m *Model) OpenPicker() error {
+	if m.DumpDir == "" {
+		return fmt.Errorf("not watching a directory (start with --dir)")
+	}
+	if err := m.RefreshDumps(); err != nil {
+		return err
+	}
+	m.PickerPos = max(m.currentDump(), 0)
+	m.Mode = ModePicker
+	return nil
+}
+
+// MovePicker moves the picker selection by delta, staying inside the list
+func (m *Model) MovePicker(delta int) {
+	m.PickerPos = max(0, min(m.PickerPos+delta, len(m.Dumps)-1))
+}
+
+// PickedDump returns the dump selected in the picker
+func (m Model) PickedDump() (DumpFile, bool) {
+	if m.PickerPos < 0 || m.PickerPos >= len(m.Dumps) {
+		return DumpFile{}, false
+	}
+	return m.Dumps[m.PickerPos], true
+}
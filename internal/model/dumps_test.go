@@ -0,0 +1,118 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dumpDir creates dumps in a directory, each written a minute after the one before
+func dumpDir(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	written := time.Now().Add(-time.Hour)
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("$enddefinitions $end\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		written = written.Add(time.Minute)
+		if err := os.Chtimes(path, written, written); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func dumpNames(dumps []DumpFile) string {
+	names := make([]string, len(dumps))
+	for i, d := range dumps {
+		names[i] = filepath.Base(d.Path)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestListDumps(t *testing.T) {
+	dir := dumpDir(t, "a.vcd", "notes.txt", "c.VCD", "b.vcd")
+	if err := os.Mkdir(filepath.Join(dir, "dir.vcd"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	dumps, err := ListDumps(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dumpNames(dumps); got != "b.vcd,c.VCD,a.vcd" {
+		t.Errorf("dumps %s, want newest first", got)
+	}
+	if _, err := ListDumps(filepath.Join(dir, "missing")); err == nil {
+		t.Error("listed a missing directory")
+	}
+}
+
+func TestNewerDump(t *testing.T) {
+	dir := dumpDir(t, "a.vcd", "b.vcd", "c.vcd")
+	tests := []struct {
+		open string
+		want string
+		err  bool
+	}{
+		{"a.vcd", "b.vcd", false},
+		{"b.vcd", "c.vcd", false},
+		{"c.vcd", "", true},
+		{"gone.vcd", "c.vcd", false}, // Not listed: the newest
+	}
+	for _, tt := range tests {
+		m := NewModel(busVCD(), filepath.Join(dir, tt.open))
+		m.DumpDir = dir
+		if err := m.RefreshDumps(); err != nil {
+			t.Fatal(err)
+		}
+		dump, err := m.NewerDump()
+		if (err != nil) != tt.err || (err == nil && filepath.Base(dump.Path) != tt.want) {
+			t.Errorf("newer than %s: %s, %v, want %s", tt.open, dump.Path, err, tt.want)
+		}
+	}
+
+	m := NewModel(busVCD(), "test.vcd")
+	if _, err := m.NewerDump(); err == nil {
+		t.Error("newer dump without --dir")
+	}
+}
+
+func TestPicker(t *testing.T) {
+	dir := dumpDir(t, "a.vcd", "b.vcd", "c.vcd")
+	m := NewModel(busVCD(), filepath.Join(dir, "b.vcd"))
+	if err := m.OpenPicker(); err == nil {
+		t.Error("picker opened without --dir")
+	}
+
+	m.DumpDir = dir
+	if err := m.OpenPicker(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Mode != ModePicker || m.PickerPos != 1 || !m.IsCurrentDump(1) {
+		t.Fatalf("picker in mode %v at %d, want at the open dump", m.Mode, m.PickerPos)
+	}
+	m.MovePicker(5)
+	if dump, ok := m.PickedDump(); !ok || filepath.Base(dump.Path) != "a.vcd" {
+		t.Errorf("picked %s past the end, want the oldest", dump.Path)
+	}
+	m.MovePicker(-5)
+	if m.PickerPos != 0 {
+		t.Errorf("picker at %d past the start", m.PickerPos)
+	}
+
+	// A dump removed under the selection
+	if err := os.Remove(filepath.Join(dir, "a.vcd")); err != nil {
+		t.Fatal(err)
+	}
+	m.MovePicker(5)
+	if err := m.RefreshDumps(); err != nil {
+		t.Fatal(err)
+	}
+	if m.PickerPos != 1 {
+		t.Errorf("picker at %d after the last dump was removed, want 1", m.PickerPos)
+	}
+}
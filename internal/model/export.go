@@ -0,0 +1,88 @@
+package model
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/hitsan/sigscope/internal/render"
+)
+
+// ExportCSV writes the listed signals over the visible time window to a CSV
+// file: one row per time at which any of them changes, buses in their radix
+func (m Model) ExportCSV(path string) (int, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := m.writeCSV(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return rows, err
+}
+
+// writeCSV writes the CSV export and returns the number of data rows
+func (m Model) writeCSV(w io.Writer) (int, error) {
+	var signals []int
+	for _, row := range m.DisplayRows() {
+		if row.Kind == RowSignal && m.SignalVisible[row.Signal] {
+			signals = append(signals, row.Signal)
+		}
+	}
+	if len(signals) == 0 {
+		return 0, fmt.Errorf("no signals to export")
+	}
+
+	out := csv.NewWriter(w)
+	header := []string{"time"}
+	for _, idx := range signals {
+		header = append(header, m.Signals[idx].Signal.FullName)
+	}
+	if err := out.Write(header); err != nil {
+		return 0, err
+	}
+
+	times := m.changeTimes(signals)
+	for _, t := range times {
+		record := []string{strconv.FormatUint(t, 10)}
+		for _, idx := range signals {
+			record = append(record, m.csvValue(idx, t))
+		}
+		if err := out.Write(record); err != nil {
+			return 0, err
+		}
+	}
+	out.Flush()
+	return len(times), out.Error()
+}
+
+// changeTimes returns the window start and every change time of the given
+// signals inside the visible window, in order
+func (m Model) changeTimes(signals []int) []uint64 {
+	seen := map[uint64]bool{m.TimeStart: true}
+	times := []uint64{m.TimeStart}
+	for _, idx := range signals {
+		for _, change := range m.Signals[idx].ChangesIn(m.TimeStart, m.TimeEnd) {
+			if !seen[change.Time] {
+				seen[change.Time] = true
+				times = append(times, change.Time)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times
+}
+
+// csvValue formats a signal's value at time t for the CSV export
+func (m Model) csvValue(idx int, t uint64) string {
+	sig := m.Signals[idx]
+	value := sig.GetValueAt(t)
+	if sig.Signal.Width == 1 {
+		return value
+	}
+	return render.FormatBusValue(value, sig.Signal.Width, m.SignalRadix(idx))
+}
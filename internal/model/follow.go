@@ -0,0 +1,99 @@
+package model
+
+import "github.com/hitsan/sigscope/pkg/waveform"
+
+// Following reports whether the dump is read incrementally as it grows
+func (m Model) Following() bool {
+	return m.Follower != nil
+}
+
+// FollowGrowth brings the view up to date after the follower appended to
+// the dump that used to end at oldEnd. Unless following is paused, the
+// window keeps its width and moves to show the latest time; a window
+// showing the whole dump keeps showing all of it.
+func (m *Model) FollowGrowth(oldEnd uint64) {
+	// Bits of expanded buses and virtual buses are copies of the data
+	if len(m.ExpandedBuses) > 0 || len(m.VirtualBuses) > 0 {
+		m.extendDerived()
+	}
+
+	if m.FollowPaused {
+		m.Zoom = m.zoomLevel()
+		return
+	}
+	m.scrollToEnd(m.TimeStart == 0 && m.TimeEnd >= oldEnd)
+}
+
+// extendDerived appends the changes the follower added since the last
+// update to the bits of expanded buses and to virtual buses
+func (m *Model) extendDerived() {
+	from := m.derivedFrom
+	if len(from) != len(m.Signals) {
+		m.rebuildSignals()
+		return
+	}
+
+	// Bit rows follow their bus MSB first, so the row position gives the bit
+	for i, sig := range m.Signals {
+		parent, ok := m.bitParent[sig.Signal.FullName]
+		if !ok {
+			continue
+		}
+		p := m.signalIndex[parent]
+		bus := m.Signals[p]
+		waveform.AppendBit(sig, bus, bus.Signal.Width-(i-p), from[p])
+	}
+
+	for _, vb := range m.VirtualBuses {
+		i, ok := m.signalIndex[vb.Name]
+		if !ok {
+			continue // Dropped when its members disappeared
+		}
+		bits := make([]*waveform.SignalData, len(vb.Bits))
+		bitsFrom := make([]int, len(vb.Bits))
+		for j, name := range vb.Bits {
+			k := m.signalIndex[name]
+			bits[j], bitsFrom[j] = m.Signals[k], from[k]
+		}
+		waveform.AppendCombined(m.Signals[i], bits, bitsFrom)
+	}
+
+	m.derivedFrom = changeCounts(m.Signals)
+}
+
+// changeCounts returns the number of changes of each signal
+func changeCounts(signals []*waveform.SignalData) []int {
+	counts := make([]int, len(signals))
+	for i, sig := range signals {
+		counts[i] = len(sig.Changes)
+	}
+	return counts
+}
+
+// ToggleFollow pauses or resumes scrolling to the latest time
+func (m *Model) ToggleFollow() {
+	if !m.Following() {
+		m.StatusMessage = "Not following the dump (start with --follow)"
+		return
+	}
+	m.FollowPaused = !m.FollowPaused
+	if m.FollowPaused {
+		m.StatusMessage = "Follow paused"
+		return
+	}
+	m.StatusMessage = "Following"
+	m.scrollToEnd(false)
+}
+
+// scrollToEnd moves the window to end at the latest time, or shows the
+// whole dump
+func (m *Model) scrollToEnd(whole bool) {
+	end := m.VCD.EndTime
+	duration := m.TimeEnd - m.TimeStart
+	if whole || duration == 0 || duration >= end {
+		m.TimeStart, m.TimeEnd = 0, end
+	} else {
+		m.TimeStart, m.TimeEnd = end-duration, end
+	}
+	m.Zoom = m.zoomLevel()
+}
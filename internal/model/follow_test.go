@@ -0,0 +1,48 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+func TestFollowGrowthExtendsDerived(t *testing.T) {
+	v := busVCD()
+	m := NewModel(v, "test.vcd")
+	m.selectByName("top.data")
+	m.ToggleBusExpansion()
+	m.PickedSignals = []string{"top.data[1]", "top.a"}
+	if err := m.CreateVirtualBus("vb"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The follower appends to the signals in place
+	grow := func(id string, changes ...waveform.ValueChange) {
+		v.Signals[id].Changes = append(v.Signals[id].Changes, changes...)
+	}
+	for _, step := range [][]waveform.ValueChange{
+		{{Time: 30, Value: "1111"}, {Time: 30, Value: "0101"}},
+		{{Time: 30, Value: "0001"}, {Time: 40, Value: "0011"}},
+		{{Time: 50, Value: "0011"}, {Time: 60, Value: "1z00"}},
+	} {
+		oldEnd := v.EndTime
+		grow("%", step...)
+		grow("!", waveform.ValueChange{Time: step[len(step)-1].Time, Value: "0"})
+		v.EndTime = step[len(step)-1].Time
+		m.FollowGrowth(oldEnd)
+
+		want := NewModel(v, "test.vcd")
+		want.ExpandedBuses = m.ExpandedBuses
+		want.VirtualBuses = m.VirtualBuses
+		want.rebuildSignals()
+		if names(m) != names(want) {
+			t.Fatalf("signals %s, want %s", names(m), names(want))
+		}
+		for i, sig := range m.Signals {
+			if !reflect.DeepEqual(sig.Changes, want.Signals[i].Changes) {
+				t.Errorf("up to %d, %s: %v, want %v", v.EndTime, sig.Signal.FullName, sig.Changes, want.Signals[i].Changes)
+			}
+		}
+	}
+}
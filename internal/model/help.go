@@ -0,0 +1,62 @@
+package model
+
+import "sort"
+
+// HelpLine is one line of the help overlay. A line with only Text is a
+// section heading; an empty line separates sections.
+type HelpLine struct {
+	Keys string // Keys, mouse action or command
+	Text string // What it does, or the heading
+}
+
+// ShowHelp opens the help overlay with the given lines
+func (m *Model) ShowHelp(lines []HelpLine) {
+	m.Help = lines
+	m.HelpScroll = 0
+	m.Mode = ModeHelp
+}
+
+// CloseHelp dismisses the help overlay
+func (m *Model) CloseHelp() {
+	m.Help = nil
+	m.Mode = ModeNormal
+}
+
+// ScrollHelp scrolls the help overlay by delta lines
+func (m *Model) ScrollHelp(delta int) {
+	m.HelpScroll += delta
+	if maxScroll := len(m.Help) - m.HelpHeight(); m.HelpScroll > maxScroll {
+		m.HelpScroll = maxScroll
+	}
+	if m.HelpScroll < 0 {
+		m.HelpScroll = 0
+	}
+}
+
+// HelpHeight returns the number of help lines shown at once: everything
+// between the title and the status bar, minus the box border
+func (m Model) HelpHeight() int {
+	height := m.ContentHeight() + m.HeaderHeight() - 1 - 2
+	if height < 1 {
+		return 1
+	}
+	return height
+}
+
+// KeysFor returns the keys bound to an action in a key map, single
+// characters first
+func KeysFor(keyMap map[string]string, action string) []string {
+	var keys []string
+	for key, name := range keyMap {
+		if name == action {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,96 @@
+package model
+
+// maxHistory bounds the number of remembered views
+const maxHistory = 100
+
+// History is a jumplist of earlier views (cursor, time window and selection).
+// pos is the entry currently shown; pos == len(entries) means the live view,
+// which is not in the list until the first step back.
+type History struct {
+	entries []ViewState
+	pos     int
+}
+
+// NavigationState snapshots the cursor, time window and selection
+func (m Model) NavigationState() ViewState {
+	return ViewState{
+		CursorTime:         m.CursorTime,
+		TimeStart:          m.TimeStart,
+		TimeEnd:            m.TimeEnd,
+		Zoom:               m.Zoom,
+		SelectedRow:        m.SelectedRowIndex(),
+		SelectedName:       m.selectedName(),
+		SignalScrollOffset: m.SignalScrollOffset,
+	}
+}
+
+// RecordJump remembers the view before a jump, if the jump changed the view.
+// Views ahead of the current position are dropped, as in a browser.
+func (m *Model) RecordJump(before ViewState) {
+	if sameView(before, m.NavigationState()) {
+		return
+	}
+	h := &m.History
+	h.entries = append(h.entries[:h.pos:h.pos], before)
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+	}
+	h.pos = len(h.entries)
+}
+
+// HistoryBack returns to the previous view; false if there is none
+func (m *Model) HistoryBack() bool {
+	h := &m.History
+	if h.pos == 0 {
+		return false
+	}
+	// Remember the live view so HistoryForward can come back to it, within
+	// the same bound as the earlier views
+	if h.pos == len(h.entries) {
+		h.entries = append(h.entries, m.NavigationState())
+		if len(h.entries) > maxHistory {
+			h.entries = h.entries[1:]
+			h.pos--
+		}
+	} else {
+		h.entries[h.pos] = m.NavigationState()
+	}
+	h.pos--
+	m.restoreNavigation(h.entries[h.pos])
+	return true
+}
+
+// HistoryForward undoes a HistoryBack; false if there is nothing ahead
+func (m *Model) HistoryForward() bool {
+	h := &m.History
+	if h.pos >= len(h.entries)-1 {
+		return false
+	}
+	h.entries[h.pos] = m.NavigationState()
+	h.pos++
+	m.restoreNavigation(h.entries[h.pos])
+	return true
+}
+
+// restoreNavigation applies the cursor, time window and selection of a view
+func (m *Model) restoreNavigation(state ViewState) {
+	m.CursorTime = min(state.CursorTime, m.VCD.EndTime)
+	m.setTimeWindow(state.TimeStart, state.TimeEnd)
+
+	if state.SelectedName == "" || !m.selectByName(state.SelectedName) {
+		m.SelectRow(state.SelectedRow)
+	}
+	m.ensureSelection()
+
+	m.SignalScrollOffset = state.SignalScrollOffset
+	m.adjustSignalScroll()
+}
+
+// sameView reports whether two views show the same cursor, window and selection
+func sameView(a, b ViewState) bool {
+	return a.CursorTime == b.CursorTime &&
+		a.TimeStart == b.TimeStart &&
+		a.TimeEnd == b.TimeEnd &&
+		a.SelectedName == b.SelectedName &&
+		a.SelectedRow == b.SelectedRow
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+)
+
+// jump moves the cursor to t, recording the view before it
+func jump(m *Model, t uint64) {
+	before := m.NavigationState()
+	m.CursorTime = t
+	m.RecordJump(before)
+}
+
+func TestHistory(t *testing.T) {
+	tests := []struct {
+		name   string
+		steps  string // Jumps to 1-9, b for back, f for forward
+		cursor uint64
+		back   string // Cursor times going back from there, until the start
+	}{
+		{"no jumps", "", 0, ""},
+		{"back", "123b", 2, "1 0"},
+		{"back to the start", "12bbb", 0, ""},
+		{"forward", "123bbf", 2, "1 0"},
+		{"forward to the live view", "123bbff", 3, "2 1 0"},
+		{"nothing ahead", "12f", 2, "1 0"},
+		{"a jump drops the views ahead", "123bb7", 7, "1 0"},
+		{"repeated view", "11", 1, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := zoomModel()
+			for _, step := range tt.steps {
+				switch step {
+				case 'b':
+					m.HistoryBack()
+				case 'f':
+					m.HistoryForward()
+				default:
+					jump(&m, uint64(step-'0'))
+				}
+			}
+			if m.CursorTime != tt.cursor {
+				t.Errorf("cursor at %d, want %d", m.CursorTime, tt.cursor)
+			}
+			var back []uint64
+			for m.HistoryBack() {
+				back = append(back, m.CursorTime)
+			}
+			if got := fmt.Sprint(back); got != "["+tt.back+"]" {
+				t.Errorf("going back %s, want [%s]", got, tt.back)
+			}
+		})
+	}
+}
+
+func TestHistoryRestoresView(t *testing.T) {
+	m := zoomModel()
+	m.selectByName("top.b")
+	before := m.NavigationState()
+	m.ZoomToRange(100, 200)
+	m.CursorTime = 150
+	m.selectByName("top.data")
+	m.RecordJump(before)
+
+	if !m.HistoryBack() {
+		t.Fatal("no view to go back to")
+	}
+	if m.TimeStart != 0 || m.TimeEnd != 1000 || m.CursorTime != 0 || m.SelectedSignalData().Signal.FullName != "top.b" {
+		t.Errorf("back at [%d, %d], cursor %d, %s", m.TimeStart, m.TimeEnd, m.CursorTime, m.SelectedSignalData().Signal.FullName)
+	}
+	if !m.HistoryForward() {
+		t.Fatal("no view to go forward to")
+	}
+	if m.TimeStart != 100 || m.TimeEnd != 200 || m.CursorTime != 150 || m.SelectedSignalData().Signal.FullName != "top.data" {
+		t.Errorf("forward at [%d, %d], cursor %d, %s", m.TimeStart, m.TimeEnd, m.CursorTime, m.SelectedSignalData().Signal.FullName)
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	m := zoomModel()
+	for i := range maxHistory + 50 {
+		jump(&m, uint64(i+1))
+	}
+	if len(m.History.entries) != maxHistory {
+		t.Errorf("%d views remembered, want %d", len(m.History.entries), maxHistory)
+	}
+	steps := 0
+	for m.HistoryBack() {
+		steps++
+		if len(m.History.entries) > maxHistory {
+			t.Fatalf("%d views remembered after a step back", len(m.History.entries))
+		}
+	}
+	// The oldest views were dropped, one more for the live view
+	if steps != maxHistory-1 || m.CursorTime != 51 {
+		t.Errorf("%d steps back to %d, want %d to 51", steps, m.CursorTime, maxHistory-1)
+	}
+	for m.HistoryForward() {
+	}
+	if m.CursorTime != maxHistory+50 {
+		t.Errorf("forward to %d, want the live view at %d", m.CursorTime, maxHistory+50)
+	}
+}
@@ -0,0 +1,568 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+)
+
+// RowKind identifies the type of a row in the signal list
+type RowKind int
+
+const (
+	RowSignal  RowKind = iota // A signal (or a bit of an expanded bus)
+	RowDivider                // A labelled divider line
+	RowGroup                  // Header of a collapsible group
+)
+
+// Entry is a node of the user-defined signal layout
+type Entry struct {
+	Kind      RowKind
+	Name      string   // Signal full name, divider label or group name
+	Collapsed bool     // Group only: members are hidden
+	Children  []*Entry // Group only: member entries in order
+}
+
+// Row is one line of the display list
+type Row struct {
+	Kind   RowKind
+	Signal int    // Global signal index (RowSignal only, -1 otherwise)
+	Entry  *Entry // Layout entry (nil for bit rows of an expanded bus)
+	Depth  int    // Group nesting depth
+}
+
+// rowCache holds the display rows of one state of the layout. Copies of a
+// model share it until one of them changes the layout, visibility or mode,
+// which gives that copy a fresh cache.
+type rowCache struct {
+	built    bool
+	rows     []Row
+	bySignal map[int]int    // Signal index -> row position
+	byEntry  map[*Entry]int // Divider or group -> row position
+}
+
+// DisplayRows returns the rows listed in the current mode, in layout order.
+// Hidden signals are listed only in select mode; members of collapsed
+// groups are never listed.
+func (m Model) DisplayRows() []Row {
+	return m.cachedRows().rows
+}
+
+// cachedRows returns the display rows and their positions, building them on
+// first use after a change
+func (m Model) cachedRows() *rowCache {
+	c := m.rows
+	if c == nil {
+		c = &rowCache{} // Model not set up by NewModel: nothing to keep it in
+	}
+	if c.built {
+		return c
+	}
+	c.rows = nil
+	m.appendRows(&c.rows, m.Layout, 0)
+	c.bySignal = make(map[int]int, len(c.rows))
+	c.byEntry = make(map[*Entry]int)
+	for i, row := range c.rows {
+		if row.Kind == RowSignal {
+			c.bySignal[row.Signal] = i
+		} else {
+			c.byEntry[row.Entry] = i
+		}
+	}
+	c.built = true
+	return c
+}
+
+// invalidateRows drops the cached display rows after a change to the layout,
+// signal visibility or mode
+func (m *Model) invalidateRows() {
+	m.rows = &rowCache{}
+}
+
+// changeLayout gives the model its own copy of the layout before a change.
+// Model values are copied on every update, and earlier copies must keep the
+// layout they had.
+func (m *Model) changeLayout() {
+	copies := make(map[*Entry]*Entry)
+	m.Layout = copyLayout(m.Layout, copies)
+	if m.selectedEntry != nil {
+		m.selectedEntry = copies[m.selectedEntry]
+	}
+	m.invalidateRows()
+}
+
+// changeVisibility gives the model its own copy of the signal visibility
+// before a change
+func (m *Model) changeVisibility() {
+	m.SignalVisible = append([]bool{}, m.SignalVisible...)
+	m.invalidateRows()
+}
+
+// appendRows flattens layout entries into display rows
+func (m Model) appendRows(rows *[]Row, entries []*Entry, depth int) {
+	for _, e := range entries {
+		switch e.Kind {
+		case RowSignal:
+			idx, ok := m.signalIndex[e.Name]
+			if !ok {
+				continue
+			}
+			if m.signalListed(idx) {
+				*rows = append(*rows, Row{Kind: RowSignal, Signal: idx, Entry: e, Depth: depth})
+			}
+			// Bit rows follow their bus
+			for _, bit := range m.busBitIndices(idx) {
+				if m.signalListed(bit) {
+					*rows = append(*rows, Row{Kind: RowSignal, Signal: bit, Depth: depth})
+				}
+			}
+		case RowDivider:
+			*rows = append(*rows, Row{Kind: RowDivider, Signal: -1, Entry: e, Depth: depth})
+		case RowGroup:
+			*rows = append(*rows, Row{Kind: RowGroup, Signal: -1, Entry: e, Depth: depth})
+			if !e.Collapsed {
+				m.appendRows(rows, e.Children, depth+1)
+			}
+		}
+	}
+}
+
+// signalListed reports whether a signal row is shown in the current mode
+func (m Model) signalListed(globalIdx int) bool {
+	return m.SelectMode || m.SignalVisible[globalIdx]
+}
+
+// busBitIndices returns the global indices of the bit rows of an expanded bus
+func (m Model) busBitIndices(globalIdx int) []int {
+	name := m.Signals[globalIdx].Signal.FullName
+	var bits []int
+	for i := globalIdx + 1; i < len(m.Signals); i++ {
+		if m.bitParent[m.Signals[i].Signal.FullName] != name {
+			break
+		}
+		bits = append(bits, i)
+	}
+	return bits
+}
+
+// OrderedSignalIndices returns every signal in layout order, including hidden
+// signals and members of collapsed groups
+func (m Model) OrderedSignalIndices() []int {
+	var indices []int
+	var walk func(entries []*Entry)
+	walk = func(entries []*Entry) {
+		for _, e := range entries {
+			switch e.Kind {
+			case RowSignal:
+				if idx, ok := m.signalIndex[e.Name]; ok {
+					indices = append(indices, idx)
+					indices = append(indices, m.busBitIndices(idx)...)
+				}
+			case RowGroup:
+				walk(e.Children)
+			}
+		}
+	}
+	walk(m.Layout)
+	return indices
+}
+
+// EntryVisible reports whether a layout entry has anything to show in normal
+// mode. Dividers and groups are always visible.
+func (m Model) EntryVisible(e *Entry) bool {
+	if e.Kind != RowSignal {
+		return true
+	}
+	idx, ok := m.signalIndex[e.Name]
+	if !ok {
+		return false
+	}
+	if m.SignalVisible[idx] {
+		return true
+	}
+	for _, bit := range m.busBitIndices(idx) {
+		if m.SignalVisible[bit] {
+			return true
+		}
+	}
+	return false
+}
+
+// entryListed reports whether a layout entry produces a row in the current mode
+func (m Model) entryListed(e *Entry) bool {
+	return m.SelectMode || m.EntryVisible(e)
+}
+
+// syncLayout drops layout entries of signals that no longer exist and
+// appends entries for signals that are not placed yet. Entries naming a bit
+// row stand for their bus.
+func (m *Model) syncLayout() {
+	m.changeLayout()
+	placed := make(map[string]bool)
+	m.Layout = m.pruneEntries(m.Layout, placed)
+	for _, sig := range m.Signals {
+		name := sig.Signal.FullName
+		if _, isBit := m.bitParent[name]; isBit || placed[name] {
+			continue
+		}
+		m.Layout = append(m.Layout, &Entry{Kind: RowSignal, Name: name})
+	}
+}
+
+// pruneEntries returns the entries that still refer to existing signals
+func (m *Model) pruneEntries(entries []*Entry, placed map[string]bool) []*Entry {
+	kept := make([]*Entry, 0, len(entries))
+	for _, e := range entries {
+		switch e.Kind {
+		case RowSignal:
+			name := e.Name
+			if parent, isBit := m.bitParent[name]; isBit {
+				name = parent
+			}
+			if _, ok := m.signalIndex[name]; !ok || placed[name] {
+				continue
+			}
+			placed[name] = true
+			e.Name = name
+		case RowGroup:
+			e.Children = m.pruneEntries(e.Children, placed)
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// SelectedRowIndex returns the position of the selected row in DisplayRows, or -1
+func (m Model) SelectedRowIndex() int {
+	c := m.cachedRows()
+	pos, ok := c.bySignal[m.SelectedSignal]
+	if m.selectedEntry != nil {
+		pos, ok = c.byEntry[m.selectedEntry]
+	}
+	if !ok {
+		return -1
+	}
+	return pos
+}
+
+// IsRowSelected reports whether a display row is the selected one
+func (m Model) IsRowSelected(row Row) bool {
+	if m.selectedEntry != nil {
+		return row.Kind != RowSignal && row.Entry == m.selectedEntry
+	}
+	return row.Kind == RowSignal && row.Signal == m.SelectedSignal
+}
+
+// SelectedEntry returns the selected divider or group, or nil when a signal is selected
+func (m Model) SelectedEntry() *Entry {
+	return m.selectedEntry
+}
+
+// SelectRow selects the display row at position i
+func (m *Model) SelectRow(i int) {
+	rows := m.DisplayRows()
+	if i < 0 || i >= len(rows) {
+		return
+	}
+	if rows[i].Kind == RowSignal {
+		m.SelectedSignal = rows[i].Signal
+		m.selectedEntry = nil
+	} else {
+		m.SelectedSignal = -1
+		m.selectedEntry = rows[i].Entry
+	}
+}
+
+// selectSignal selects a signal row, opening collapsed groups that contain it
+func (m *Model) selectSignal(globalIdx int) {
+	m.SelectedSignal = globalIdx
+	m.selectedEntry = nil
+
+	name := m.Signals[globalIdx].Signal.FullName
+	if parent, isBit := m.bitParent[name]; isBit {
+		name = parent
+	}
+	path := m.groupPath(m.Layout, name)
+	if !slices.ContainsFunc(path, func(g *Entry) bool { return g.Collapsed }) {
+		return
+	}
+	m.changeLayout()
+	for _, g := range m.groupPath(m.Layout, name) {
+		g.Collapsed = false
+	}
+}
+
+// groupPath returns the groups enclosing the entry of the named signal
+func (m Model) groupPath(entries []*Entry, name string) []*Entry {
+	for _, e := range entries {
+		switch e.Kind {
+		case RowSignal:
+			if e.Name == name {
+				return []*Entry{}
+			}
+		case RowGroup:
+			if path := m.groupPath(e.Children, name); path != nil {
+				return append([]*Entry{e}, path...)
+			}
+		}
+	}
+	return nil
+}
+
+// ensureSelection selects the first row when the selected row is not listed
+func (m *Model) ensureSelection() {
+	if m.SelectedRowIndex() < 0 {
+		m.SelectRow(0)
+	}
+}
+
+// selectedLayoutEntry returns the layout entry of the selected row. Bit rows
+// resolve to their bus.
+func (m Model) selectedLayoutEntry() *Entry {
+	if m.selectedEntry != nil {
+		return m.selectedEntry
+	}
+	sig := m.SelectedSignalData()
+	if sig == nil {
+		return nil
+	}
+	name := sig.Signal.FullName
+	if parent, isBit := m.bitParent[name]; isBit {
+		name = parent
+	}
+	return findSignalEntry(m.Layout, name)
+}
+
+// findSignalEntry returns the layout entry of the named signal
+func findSignalEntry(entries []*Entry, name string) *Entry {
+	for _, e := range entries {
+		if e.Kind == RowSignal && e.Name == name {
+			return e
+		}
+		if e.Kind == RowGroup {
+			if found := findSignalEntry(e.Children, name); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// locate returns the group containing target (nil for the top level) and its
+// position there. The position is -1 if target is not in the layout.
+func (m *Model) locate(target *Entry) (*Entry, int) {
+	var walk func(parent *Entry, entries []*Entry) (*Entry, int)
+	walk = func(parent *Entry, entries []*Entry) (*Entry, int) {
+		for i, e := range entries {
+			if e == target {
+				return parent, i
+			}
+			if e.Kind == RowGroup {
+				if p, idx := walk(e, e.Children); idx >= 0 {
+					return p, idx
+				}
+			}
+		}
+		return nil, -1
+	}
+	return walk(nil, m.Layout)
+}
+
+// children returns the entry list of a group, or the top level for nil
+func (m *Model) children(parent *Entry) *[]*Entry {
+	if parent == nil {
+		return &m.Layout
+	}
+	return &parent.Children
+}
+
+// insertEntry inserts e into list at position i
+func insertEntry(list *[]*Entry, i int, e *Entry) {
+	*list = append(*list, nil)
+	copy((*list)[i+1:], (*list)[i:])
+	(*list)[i] = e
+}
+
+// removeEntry removes the entry at position i from list
+func removeEntry(list *[]*Entry, i int) {
+	*list = append((*list)[:i], (*list)[i+1:]...)
+}
+
+// MoveRowUp moves the selected row up past the previous row.
+// At the top of a group the row leaves the group; an expanded group above is entered.
+func (m *Model) MoveRowUp() {
+	m.moveRow(-1)
+}
+
+// MoveRowDown moves the selected row down past the next row.
+// At the bottom of a group the row leaves the group; an expanded group below is entered.
+func (m *Model) MoveRowDown() {
+	m.moveRow(1)
+}
+
+// moveRow moves the selected layout entry one listed sibling in direction dir
+func (m *Model) moveRow(dir int) {
+	m.changeLayout()
+	e := m.selectedLayoutEntry()
+	if e == nil {
+		return
+	}
+	parent, idx := m.locate(e)
+	if idx < 0 {
+		return
+	}
+	list := m.children(parent)
+
+	// Skip siblings that are not listed (hidden signals)
+	n := idx + dir
+	for n >= 0 && n < len(*list) && !m.entryListed((*list)[n]) {
+		n += dir
+	}
+
+	switch {
+	case n < 0 || n >= len(*list):
+		if parent == nil {
+			return
+		}
+		// Leave the group, placing the entry just before or after it
+		removeEntry(list, idx)
+		grandparent, gidx := m.locate(parent)
+		if dir > 0 {
+			gidx++
+		}
+		insertEntry(m.children(grandparent), gidx, e)
+	case (*list)[n].Kind == RowGroup && !(*list)[n].Collapsed:
+		// Enter an expanded group at its near edge
+		group := (*list)[n]
+		removeEntry(list, idx)
+		if dir > 0 {
+			insertEntry(&group.Children, 0, e)
+		} else {
+			insertEntry(&group.Children, len(group.Children), e)
+		}
+	default:
+		removeEntry(list, idx)
+		insertEntry(list, n, e)
+	}
+	m.adjustSignalScroll()
+}
+
+// InsertDivider adds a labelled divider below the selected row
+func (m *Model) InsertDivider(label string) {
+	e := &Entry{Kind: RowDivider, Name: label}
+	m.insertBelowSelection(e)
+	m.selectedEntry = e
+	m.SelectedSignal = -1
+	m.adjustSignalScroll()
+}
+
+// insertBelowSelection places a new entry right below the selected row
+func (m *Model) insertBelowSelection(e *Entry) {
+	m.changeLayout()
+	sel := m.selectedLayoutEntry()
+	if sel == nil {
+		m.Layout = append(m.Layout, e)
+		return
+	}
+	if sel.Kind == RowGroup && !sel.Collapsed {
+		insertEntry(&sel.Children, 0, e)
+		return
+	}
+	parent, idx := m.locate(sel)
+	insertEntry(m.children(parent), idx+1, e)
+}
+
+// CreateGroup wraps the selected row in a new group. Neighbouring rows can
+// then be moved into the group.
+func (m *Model) CreateGroup(name string) error {
+	m.changeLayout()
+	sel := m.selectedLayoutEntry()
+	if sel == nil {
+		return fmt.Errorf("nothing to group")
+	}
+	if name == "" {
+		name = fmt.Sprintf("group%d", countGroups(m.Layout))
+	}
+	parent, idx := m.locate(sel)
+	if idx < 0 {
+		return fmt.Errorf("nothing to group")
+	}
+
+	group := &Entry{Kind: RowGroup, Name: name, Children: []*Entry{sel}}
+	(*m.children(parent))[idx] = group
+	m.selectedEntry = group
+	m.SelectedSignal = -1
+	m.adjustSignalScroll()
+	return nil
+}
+
+// countGroups returns the number of groups in the layout
+func countGroups(entries []*Entry) int {
+	count := 0
+	for _, e := range entries {
+		if e.Kind == RowGroup {
+			count += 1 + countGroups(e.Children)
+		}
+	}
+	return count
+}
+
+// RemoveSelectedEntry deletes the selected divider, or ungroups the selected
+// group by moving its members into the enclosing level
+func (m *Model) RemoveSelectedEntry() {
+	if m.selectedEntry == nil {
+		return
+	}
+	pos := m.SelectedRowIndex()
+	m.changeLayout()
+	e := m.selectedEntry
+	parent, idx := m.locate(e)
+	if idx < 0 {
+		return
+	}
+	list := m.children(parent)
+	removeEntry(list, idx)
+	if e.Kind == RowGroup {
+		for i, child := range e.Children {
+			insertEntry(list, idx+i, child)
+		}
+	}
+
+	m.selectedEntry = nil
+	m.SelectedSignal = -1
+	if rows := m.DisplayRows(); pos >= len(rows) {
+		pos = len(rows) - 1
+	}
+	m.SelectRow(pos)
+	m.adjustSignalScroll()
+}
+
+// ToggleGroupCollapse collapses or expands the selected group.
+// It returns false when no group is selected.
+func (m *Model) ToggleGroupCollapse() bool {
+	if m.selectedEntry == nil || m.selectedEntry.Kind != RowGroup {
+		return false
+	}
+	m.changeLayout()
+	e := m.selectedEntry
+	e.Collapsed = !e.Collapsed
+	m.adjustSignalScroll()
+	return true
+}
+
+// copyLayout returns a deep copy of layout entries, recording in copies
+// (unless nil) the copy of each entry
+func copyLayout(entries []*Entry, copies map[*Entry]*Entry) []*Entry {
+	if entries == nil {
+		return nil
+	}
+	copied := make([]*Entry, len(entries))
+	for i, e := range entries {
+		c := *e
+		c.Children = copyLayout(e.Children, copies)
+		copied[i] = &c
+		if copies != nil {
+			copies[e] = &c
+		}
+	}
+	return copied
+}
@@ -0,0 +1,287 @@
+package model
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// rowNames describes the display rows: signals by name, "-label" for
+// dividers, "+name" for expanded and "*name" for collapsed groups, indented
+// by depth
+func rowNames(m Model) string {
+	var names []string
+	for _, row := range m.DisplayRows() {
+		name := strings.Repeat(" ", row.Depth)
+		switch row.Kind {
+		case RowSignal:
+			name += strings.TrimPrefix(m.Signals[row.Signal].Signal.FullName, "top.")
+		case RowDivider:
+			name += "-" + row.Entry.Name
+		case RowGroup:
+			if row.Entry.Collapsed {
+				name += "*" + row.Entry.Name
+			} else {
+				name += "+" + row.Entry.Name
+			}
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ",")
+}
+
+// selectName selects the row described as by rowNames
+func selectName(t *testing.T, m *Model, name string) {
+	t.Helper()
+	for i, row := range strings.Split(rowNames(*m), ",") {
+		if strings.TrimSpace(row) == name {
+			m.SelectRow(i)
+			return
+		}
+	}
+	t.Fatalf("no row %s in %s", name, rowNames(*m))
+}
+
+func TestLayoutEditing(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(t *testing.T, m *Model)
+		want string
+	}{
+		{
+			name: "divider below the selection",
+			edit: func(t *testing.T, m *Model) {
+				selectName(t, m, "b")
+				m.InsertDivider("ctl")
+			},
+			want: "a,b,-ctl,c,data",
+		},
+		{
+			name: "group around the selection",
+			edit: func(t *testing.T, m *Model) {
+				selectName(t, m, "b")
+				if err := m.CreateGroup("g"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "a,+g, b,c,data",
+		},
+		{
+			name: "divider as the first member of an expanded group",
+			edit: func(t *testing.T, m *Model) {
+				selectName(t, m, "b")
+				m.CreateGroup("")
+				m.InsertDivider("ctl")
+			},
+			want: "a,+group0, -ctl, b,c,data",
+		},
+		{
+			name: "divider below a collapsed group",
+			edit: func(t *testing.T, m *Model) {
+				selectName(t, m, "b")
+				m.CreateGroup("g")
+				m.ToggleGroupCollapse()
+				m.InsertDivider("ctl")
+			},
+			want: "a,*g,-ctl,c,data",
+		},
+		{
+			name: "rows move into and out of groups",
+			edit: func(t *testing.T, m *Model) {
+				selectName(t, m, "b")
+				m.CreateGroup("g")
+				selectName(t, m, "c")
+				m.MoveRowUp() // Enters g at its end
+				selectName(t, m, "a")
+				m.MoveRowDown() // Past the expanded group's header, into it
+				selectName(t, m, "c")
+				m.MoveRowDown() // Leaves g
+			},
+			want: "+g, a, b,c,data",
+		},
+		{
+			name: "collapsed groups are moved past",
+			edit: func(t *testing.T, m *Model) {
+				selectName(t, m, "b")
+				m.CreateGroup("g")
+				m.ToggleGroupCollapse()
+				selectName(t, m, "a")
+				m.MoveRowDown()
+			},
+			want: "*g,a,c,data",
+		},
+		{
+			name: "nested groups",
+			edit: func(t *testing.T, m *Model) {
+				selectName(t, m, "b")
+				m.CreateGroup("inner")
+				m.CreateGroup("outer")
+				selectName(t, m, "c")
+				m.MoveRowUp()
+			},
+			want: "a,+outer, +inner,  b, c,data",
+		},
+		{
+			name: "ungrouping keeps the members in place",
+			edit: func(t *testing.T, m *Model) {
+				selectName(t, m, "b")
+				m.CreateGroup("g")
+				selectName(t, m, "c")
+				m.MoveRowUp()
+				selectName(t, m, "+g")
+				m.RemoveSelectedEntry()
+			},
+			want: "a,b,c,data",
+		},
+		{
+			name: "removing a divider",
+			edit: func(t *testing.T, m *Model) {
+				selectName(t, m, "a")
+				m.InsertDivider("x")
+				m.RemoveSelectedEntry()
+			},
+			want: "a,b,c,data",
+		},
+		{
+			name: "hidden signals are skipped",
+			edit: func(t *testing.T, m *Model) {
+				selectName(t, m, "b")
+				m.ToggleSignalVisibility()
+				selectName(t, m, "c")
+				m.MoveRowUp()
+			},
+			want: "c,a,data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel(busVCD(), "test.vcd")
+			tt.edit(t, &m)
+			if got := rowNames(m); got != tt.want {
+				t.Errorf("rows %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollapsedGroupSelection(t *testing.T) {
+	m := NewModel(busVCD(), "test.vcd")
+	selectName(t, &m, "c")
+	m.CreateGroup("g")
+	m.ToggleGroupCollapse()
+	if got := rowNames(m); got != "a,b,*g,data" {
+		t.Fatalf("rows %q", got)
+	}
+
+	// Selecting a hidden member, as a search does, opens its group
+	m.selectSignal(2)
+	if got := rowNames(m); got != "a,b,+g, c,data" {
+		t.Errorf("rows %q after selecting c", got)
+	}
+	if m.SelectedRowIndex() != 3 {
+		t.Errorf("selected row %d, want 3", m.SelectedRowIndex())
+	}
+
+	// Select mode lists hidden signals too
+	m.selectSignal(0)
+	m.ToggleSignalVisibility()
+	m.EnterSelectMode()
+	if got := rowNames(m); got != "a,b,+g, c,data" {
+		t.Errorf("rows %q in select mode", got)
+	}
+	if got := len(m.OrderedSignalIndices()); got != 4 {
+		t.Errorf("%d ordered signals, want 4", got)
+	}
+}
+
+func TestLayoutWithExpandedBus(t *testing.T) {
+	m := NewModel(busVCD(), "test.vcd")
+	selectName(t, &m, "data")
+	m.CreateGroup("g")
+	selectName(t, &m, "data")
+	m.ToggleBusExpansion()
+	want := "a,b,c,+g, data, data[3], data[2], data[1], data[0]"
+	if got := rowNames(m); got != want {
+		t.Fatalf("rows %q, want %q", got, want)
+	}
+
+	// Moving a bit row moves its bus
+	selectName(t, &m, "data[1]")
+	m.MoveRowUp()
+	want = "a,b,c,data,data[3],data[2],data[1],data[0],+g"
+	if got := rowNames(m); got != want {
+		t.Errorf("rows %q, want %q", got, want)
+	}
+}
+
+func TestLayoutCopies(t *testing.T) {
+	m := NewModel(busVCD(), "test.vcd")
+	selectName(t, &m, "b")
+	m.CreateGroup("g")
+	saved := m
+	want := rowNames(saved)
+
+	// Every edit of a copy leaves the earlier model as it was
+	edits := []func(m *Model){
+		func(m *Model) { m.ToggleGroupCollapse() },
+		func(m *Model) { m.InsertDivider("ctl") },
+		func(m *Model) { m.CreateGroup("outer") },
+		func(m *Model) { m.RemoveSelectedEntry() },
+		func(m *Model) { m.SelectRow(2); m.MoveRowUp() },
+		func(m *Model) { m.SelectRow(0); m.ToggleSignalVisibility() },
+		func(m *Model) { m.SetAllSignalsVisible(false) },
+	}
+	for i, edit := range edits {
+		edited := saved
+		edit(&edited)
+		if rowNames(edited) == want {
+			t.Errorf("edit %d had no effect", i)
+		}
+		if got := rowNames(saved); got != want {
+			t.Fatalf("edit %d changed the earlier model's rows to %q, want %q", i, got, want)
+		}
+		if saved.SelectedEntry().Name != "g" || saved.SelectedRowIndex() != 1 {
+			t.Fatalf("edit %d changed the earlier model's selection", i)
+		}
+	}
+}
+
+func TestDisplayRowsCache(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	m := NewModel(testVCD(50), "test.vcd")
+	withDividersAndGroups(rng, &m)
+
+	edits := []func(){
+		func() { m.MoveRowUp() },
+		func() { m.MoveRowDown() },
+		func() { m.InsertDivider("d") },
+		func() { m.CreateGroup("") },
+		func() { m.RemoveSelectedEntry() },
+		func() { m.ToggleGroupCollapse() },
+		func() { m.ToggleSignalVisibility() },
+		func() { m.ToggleSelectMode() },
+		func() { m.selectSignal(rng.Intn(len(m.Signals))) },
+		func() { m.ToggleBusExpansion() },
+	}
+	for i := range 2000 {
+		m.SelectRow(rng.Intn(len(m.DisplayRows())))
+		edits[rng.Intn(len(edits))]()
+
+		var want []Row
+		m.appendRows(&want, m.Layout, 0)
+		if !reflect.DeepEqual(m.DisplayRows(), want) {
+			t.Fatalf("step %d: cached rows differ from the layout", i)
+		}
+		selected := -1
+		for pos, row := range want {
+			if m.IsRowSelected(row) {
+				selected = pos
+				break
+			}
+		}
+		if got := m.SelectedRowIndex(); got != selected {
+			t.Fatalf("step %d: selected row %d, want %d", i, got, selected)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hitsan/sigscope/internal/render"
+)
+
+// Marker is a named time position placed by the user
+type Marker struct {
+	Name string // Single letter label (A-Z)
+	Time uint64
+}
+
+// ToggleMarker places a marker at the cursor, or removes the marker already there
+func (m *Model) ToggleMarker() {
+	for i, mk := range m.Markers {
+		if mk.Time == m.CursorTime {
+			m.Markers = append(m.Markers[:i:i], m.Markers[i+1:]...)
+			m.StatusMessage = fmt.Sprintf("Removed marker %s", mk.Name)
+			return
+		}
+	}
+
+	name := m.nextMarkerName()
+	if name == "" {
+		m.StatusMessage = "All markers A-Z are in use"
+		return
+	}
+	m.SetMarker(name, m.CursorTime)
+	m.StatusMessage = fmt.Sprintf("Marker %s at %d", name, m.CursorTime)
+}
+
+// SetMarker places (or moves) the named marker
+func (m *Model) SetMarker(name string, t uint64) {
+	for i := range m.Markers {
+		if m.Markers[i].Name == name {
+			m.Markers[i].Time = t
+			m.sortMarkers()
+			return
+		}
+	}
+	m.Markers = append(m.Markers, Marker{Name: name, Time: t})
+	m.sortMarkers()
+}
+
+// NextMarker moves the cursor to the next marker after it, wrapping around
+func (m *Model) NextMarker() {
+	if len(m.Markers) == 0 {
+		return
+	}
+	target := m.Markers[0]
+	for _, mk := range m.Markers {
+		if mk.Time > m.CursorTime {
+			target = mk
+			break
+		}
+	}
+	m.CursorTime = target.Time
+	m.ensureCursorVisible()
+}
+
+// nextMarkerName returns the first unused marker letter
+func (m Model) nextMarkerName() string {
+	used := make(map[string]bool, len(m.Markers))
+	for _, mk := range m.Markers {
+		used[mk.Name] = true
+	}
+	for c := 'A'; c <= 'Z'; c++ {
+		if !used[string(c)] {
+			return string(c)
+		}
+	}
+	return ""
+}
+
+// sortMarkers keeps markers ordered by time
+func (m *Model) sortMarkers() {
+	sort.SliceStable(m.Markers, func(i, j int) bool {
+		return m.Markers[i].Time < m.Markers[j].Time
+	})
+}
+
+// SignalRadix returns the display radix of a signal
+func (m Model) SignalRadix(globalIdx int) render.Radix {
+	if globalIdx >= 0 && globalIdx < len(m.Signals) {
+		if r, ok := m.Radix[m.Signals[globalIdx].Signal.FullName]; ok {
+			return r
+		}
+	}
+	if m.DefaultRadix != "" {
+		return m.DefaultRadix
+	}
+	return render.RadixHex
+}
+
+// SetSignalRadix sets the display radix of the selected signal
+func (m *Model) SetSignalRadix(radix render.Radix) {
+	sig := m.SelectedSignalData()
+	if sig == nil || sig.Signal.Width == 1 {
+		return
+	}
+	if m.Radix == nil {
+		m.Radix = make(map[string]render.Radix)
+	}
+	m.Radix[sig.Signal.FullName] = radix
+	m.StatusMessage = fmt.Sprintf("%s: %s", sig.Signal.Name, radix)
+}
+
+// CycleSignalRadix switches the selected bus to the next display radix
+func (m *Model) CycleSignalRadix() {
+	m.SetSignalRadix(render.NextRadix(m.SignalRadix(m.SelectedSignal)))
+}
+
+// copyRadix returns a copy of the per-signal radix settings
+func (m Model) copyRadix() map[string]render.Radix {
+	radix := make(map[string]render.Radix, len(m.Radix))
+	for name, r := range m.Radix {
+		radix[name] = r
+	}
+	return radix
+}
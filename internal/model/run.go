@@ -0,0 +1,73 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxRunLog bounds the output lines kept from a run
+const maxRunLog = 1000
+
+// RunLogLines is the height of the log pane, including its title line
+const RunLogLines = 8
+
+// RunState holds the rebuild command and the output of its last run
+type RunState struct {
+	Command    string   // Shell command started by the run key ("" = none)
+	Running    bool     // A run is in progress
+	Log        []string // Output of the last run, stdout and stderr interleaved
+	LogVisible bool     // Log pane shown below the waveforms
+	Result     string   // Outcome of the last run, e.g. "exit 0 in 1.2s"
+}
+
+// StartRun prepares for a run of the rebuild command
+func (m *Model) StartRun() error {
+	if m.Run.Command == "" {
+		return fmt.Errorf("no run command (start with --run or set run in the config)")
+	}
+	if m.Run.Running {
+		return fmt.Errorf("already running: %s", m.Run.Command)
+	}
+	m.Run.Running = true
+	m.Run.Log = nil
+	m.Run.Result = ""
+	m.StatusMessage = "Running: " + m.Run.Command
+	return nil
+}
+
+// AppendRunLog adds an output line of the running command
+func (m *Model) AppendRunLog(line string) {
+	m.Run.Log = append(m.Run.Log, line)
+	if len(m.Run.Log) > maxRunLog {
+		m.Run.Log = m.Run.Log[len(m.Run.Log)-maxRunLog:]
+	}
+}
+
+// FinishRun records the outcome of a run and reports whether it succeeded.
+// A failed run opens the log pane.
+func (m *Model) FinishRun(err error, elapsed time.Duration) bool {
+	m.Run.Running = false
+	elapsed = elapsed.Round(100 * time.Millisecond)
+	if err != nil {
+		m.Run.Result = fmt.Sprintf("failed: %v after %s", err, elapsed)
+		m.Run.LogVisible = true
+	} else {
+		m.Run.Result = fmt.Sprintf("exit 0 in %s", elapsed)
+	}
+	m.StatusMessage = "Run " + m.Run.Result
+	return err == nil
+}
+
+// ToggleRunLog shows or hides the log pane
+func (m *Model) ToggleRunLog() {
+	m.Run.LogVisible = !m.Run.LogVisible
+	m.adjustSignalScroll()
+}
+
+// FooterHeight returns the number of lines below the signal rows
+func (m Model) FooterHeight() int {
+	if m.Run.LogVisible {
+		return FooterLines + RunLogLines
+	}
+	return FooterLines
+}
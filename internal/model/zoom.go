@@ -0,0 +1,244 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+// minZoomLevel keeps zooming out from going past the whole dump
+const minZoomLevel = 1.0
+
+// ZoomIn halves the visible duration around the cursor
+func (m *Model) ZoomIn() {
+	m.zoomAround(m.zoomCenter(), 2)
+}
+
+// ZoomOut doubles the visible duration around the cursor
+func (m *Model) ZoomOut() {
+	m.zoomAround(m.zoomCenter(), 0.5)
+}
+
+// ResetZoom shows the entire waveform
+func (m *Model) ResetZoom() {
+	m.setTimeWindow(0, m.VCD.EndTime)
+}
+
+// SetZoomLevel shows 1/level of the dump, centered on the cursor
+func (m *Model) SetZoomLevel(level float64) error {
+	if level < minZoomLevel {
+		return fmt.Errorf("zoom level must be at least %.0f", minZoomLevel)
+	}
+	duration := float64(m.VCD.EndTime) / level
+	if duration < 1 {
+		return fmt.Errorf("zoom level %g is beyond one time unit", level)
+	}
+	m.centerWindow(m.zoomCenter(), duration)
+	return nil
+}
+
+// ZoomAt zooms by factor while keeping time t at the same screen position
+func (m *Model) ZoomAt(t uint64, factor float64) {
+	duration := float64(m.TimeEnd - m.TimeStart)
+	newDuration := m.clampDuration(duration / factor)
+	if duration == 0 || newDuration == duration {
+		return
+	}
+
+	// Keep t at the same fraction of the window
+	fraction := float64(t-m.TimeStart) / duration
+	start := float64(t) - fraction*newDuration
+	if start < 0 {
+		start = 0
+	}
+	m.setTimeWindow(uint64(start), uint64(start+newDuration))
+}
+
+// CenterOn moves the window to center time t, keeping the zoom level
+func (m *Model) CenterOn(t uint64) {
+	m.centerWindow(t, float64(m.TimeEnd-m.TimeStart))
+}
+
+// ZoomToRange shows exactly the time range [t1, t2]
+func (m *Model) ZoomToRange(t1, t2 uint64) {
+	if t1 > t2 {
+		t1, t2 = t2, t1
+	}
+	if t2 == t1 {
+		t2 = t1 + 1
+	}
+	m.setTimeWindow(t1, t2)
+}
+
+// ZoomToSignalActivity shows the span from the first to the last transition
+// of the selected signal, with a small margin on both sides
+func (m *Model) ZoomToSignalActivity() error {
+	sig := m.SelectedSignalData()
+	if sig == nil {
+		return fmt.Errorf("no signal selected")
+	}
+
+	var first, last uint64
+	found := false
+	prev := ""
+	for i, change := range sig.Changes {
+		// The initial value at time 0 is not a transition
+		isTransition := i > 0 && change.Value != prev || i == 0 && change.Time > 0
+		prev = change.Value
+		if !isTransition {
+			continue
+		}
+		if !found {
+			first = change.Time
+			found = true
+		}
+		last = change.Time
+	}
+	if !found {
+		return fmt.Errorf("%s has no activity", sig.Signal.Name)
+	}
+
+	margin := (last - first) / 20
+	if margin < 1 {
+		margin = 1
+	}
+	start := uint64(0)
+	if first > margin {
+		start = first - margin
+	}
+	m.ZoomToRange(start, last+margin)
+	return nil
+}
+
+// ZoomTo applies a zoom entered as text:
+//
+//	""        span between the first and last marker
+//	"4x"      zoom level relative to the whole dump
+//	"100ns"   window duration, centered on the cursor
+//	"t1 t2"   time range; each end is a time ("1500", "2.5us") or a marker name
+func (m *Model) ZoomTo(spec string) error {
+	spec = strings.TrimSpace(spec)
+
+	if spec == "" {
+		if len(m.Markers) < 2 {
+			return fmt.Errorf("place two markers or enter a range")
+		}
+		m.ZoomToRange(m.Markers[0].Time, m.Markers[len(m.Markers)-1].Time)
+		return nil
+	}
+
+	fields := strings.Fields(strings.NewReplacer("..", " ", ",", " ").Replace(spec))
+	if len(fields) == 1 {
+		// A number followed by x, so that marker X is never read as a level
+		if level, ok := strings.CutSuffix(fields[0], "x"); ok {
+			if value, err := strconv.ParseFloat(level, 64); err == nil {
+				return m.SetZoomLevel(value)
+			}
+		}
+		duration, err := waveform.ParseTime(fields[0], m.VCD.Timescale)
+		if err != nil {
+			return err
+		}
+		if duration == 0 {
+			return fmt.Errorf("zoom duration must be positive")
+		}
+		m.centerWindow(m.zoomCenter(), m.clampDuration(float64(duration)))
+		return nil
+	}
+	if len(fields) != 2 {
+		return fmt.Errorf("enter two times or markers, e.g. \"100ns 250ns\" or \"A B\"")
+	}
+	t1, err := m.ResolveTime(fields[0])
+	if err != nil {
+		return err
+	}
+	t2, err := m.ResolveTime(fields[1])
+	if err != nil {
+		return err
+	}
+	m.ZoomToRange(t1, t2)
+	return nil
+}
+
+// ResolveTime converts a marker name or a time string to dump units
+func (m Model) ResolveTime(s string) (uint64, error) {
+	if t, ok := m.markerTime(s); ok {
+		return t, nil
+	}
+	return waveform.ParseTime(s, m.VCD.Timescale)
+}
+
+// markerTime returns the time of a named marker
+func (m Model) markerTime(name string) (uint64, bool) {
+	for _, mk := range m.Markers {
+		if mk.Name == strings.ToUpper(name) {
+			return mk.Time, true
+		}
+	}
+	return 0, false
+}
+
+// zoomCenter returns the cursor if visible, otherwise the window center
+func (m Model) zoomCenter() uint64 {
+	if m.CursorTime >= m.TimeStart && m.CursorTime <= m.TimeEnd {
+		return m.CursorTime
+	}
+	return (m.TimeStart + m.TimeEnd) / 2
+}
+
+// zoomAround zooms by factor, centering the window on t
+func (m *Model) zoomAround(t uint64, factor float64) {
+	m.centerWindow(t, m.clampDuration(float64(m.TimeEnd-m.TimeStart)/factor))
+}
+
+// centerWindow shows a window of the given duration centered on t
+func (m *Model) centerWindow(t uint64, duration float64) {
+	half := uint64(duration / 2)
+	start := uint64(0)
+	if t > half {
+		start = t - half
+	}
+	m.setTimeWindow(start, start+uint64(math.Round(duration)))
+}
+
+// clampDuration limits a window duration to between one time unit and the whole dump
+func (m Model) clampDuration(duration float64) float64 {
+	if maxDuration := float64(m.VCD.EndTime) / minZoomLevel; duration > maxDuration {
+		duration = maxDuration
+	}
+	if duration < 1 {
+		duration = 1
+	}
+	return duration
+}
+
+// setTimeWindow sets the visible window, shifted back inside the dump if
+// needed, and updates the zoom level to match
+func (m *Model) setTimeWindow(start, end uint64) {
+	if end > m.VCD.EndTime {
+		duration := end - start
+		end = m.VCD.EndTime
+		if end > duration {
+			start = end - duration
+		} else {
+			start = 0
+		}
+	}
+	if start >= end {
+		return
+	}
+	m.TimeStart = start
+	m.TimeEnd = end
+	m.Zoom = m.zoomLevel()
+}
+
+// zoomLevel returns how many times the window fits into the whole dump
+func (m Model) zoomLevel() float64 {
+	if m.TimeEnd <= m.TimeStart || m.VCD.EndTime == 0 {
+		return 1.0
+	}
+	return float64(m.VCD.EndTime) / float64(m.TimeEnd-m.TimeStart)
+}
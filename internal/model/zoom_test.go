@@ -0,0 +1,100 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+// zoomModel returns a model of a 1000ns dump showing all of it
+func zoomModel() Model {
+	v := busVCD()
+	v.EndTime = 1000
+	v.Timescale = "1ns"
+	v.Signals["!"].Changes = []waveform.ValueChange{{Time: 0, Value: "0"}, {Time: 200, Value: "1"}, {Time: 300, Value: "1"}, {Time: 600, Value: "0"}}
+	m := NewModel(v, "test.vcd")
+	m.ResetZoom()
+	return m
+}
+
+func TestZoomTo(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       string
+		markers    map[string]uint64
+		cursor     uint64
+		start, end uint64
+		wantErr    bool
+	}{
+		{"times", "100 300", nil, 0, 100, 300, false},
+		{"times reversed", "300 100", nil, 0, 100, 300, false},
+		{"units", "0.1us..250ns", nil, 0, 100, 250, false},
+		{"markers", "a, B", map[string]uint64{"A": 400, "B": 700}, 0, 400, 700, false},
+		{"marker and time", "A 900", map[string]uint64{"A": 400}, 0, 400, 900, false},
+		{"between the outer markers", "", map[string]uint64{"C": 800, "A": 100, "B": 500}, 0, 100, 800, false},
+		{"level around the cursor", "4x", nil, 500, 375, 625, false},
+		{"level kept inside the dump", "2x", nil, 900, 500, 1000, false},
+		{"past the end", "900 2000", nil, 0, 0, 1000, false},
+		{"one marker", "", map[string]uint64{"A": 100}, 0, 0, 1000, true},
+		{"unknown marker", "A Q", map[string]uint64{"A": 100}, 0, 0, 1000, true},
+		{"duration around the cursor", "0.2us", nil, 500, 400, 600, false},
+		{"zero duration", "0", nil, 0, 0, 1000, true},
+		{"three ends", "100 200 300", nil, 0, 0, 1000, true},
+		{"level below one", "0.5x", nil, 0, 0, 1000, true},
+		{"level too deep", "5000x", nil, 0, 0, 1000, true},
+		{"marker x", "A x", map[string]uint64{"A": 100, "X": 300}, 0, 100, 300, false},
+		{"marker x first", "x..A", map[string]uint64{"A": 100, "X": 300}, 0, 100, 300, false},
+		{"level and a time", "2x 100", nil, 0, 0, 1000, true},
+		{"not a level", "fourx", nil, 0, 0, 1000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := zoomModel()
+			for name, time := range tt.markers {
+				m.SetMarker(name, time)
+			}
+			m.CursorTime = tt.cursor
+			err := m.ZoomTo(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v", err)
+			}
+			if m.TimeStart != tt.start || m.TimeEnd != tt.end {
+				t.Errorf("window [%d, %d], want [%d, %d]", m.TimeStart, m.TimeEnd, tt.start, tt.end)
+			}
+			if want := 1000 / float64(tt.end-tt.start); m.Zoom != want {
+				t.Errorf("zoom %g, want %g", m.Zoom, want)
+			}
+		})
+	}
+}
+
+func TestZoomToSignalActivity(t *testing.T) {
+	m := zoomModel()
+	m.selectByName("top.a")
+	if err := m.ZoomToSignalActivity(); err != nil {
+		t.Fatal(err)
+	}
+	// Transitions at 200 and 600, with a margin of a twentieth
+	if m.TimeStart != 180 || m.TimeEnd != 620 {
+		t.Errorf("window [%d, %d], want [180, 620]", m.TimeStart, m.TimeEnd)
+	}
+
+	m.selectByName("top.c")
+	if err := m.ZoomToSignalActivity(); err == nil {
+		t.Error("zoomed to a signal without transitions")
+	}
+}
+
+func TestZoomInOut(t *testing.T) {
+	m := zoomModel()
+	m.CursorTime = 100
+	m.ZoomIn()
+	if m.TimeStart != 0 || m.TimeEnd != 500 || m.Zoom != 2 {
+		t.Errorf("zoomed in to [%d, %d] at %g", m.TimeStart, m.TimeEnd, m.Zoom)
+	}
+	m.ZoomOut()
+	m.ZoomOut()
+	if m.TimeStart != 0 || m.TimeEnd != 1000 || m.Zoom != 1 {
+		t.Errorf("zoomed out to [%d, %d] at %g", m.TimeStart, m.TimeEnd, m.Zoom)
+	}
+}
@@ -0,0 +1,136 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+// CellKind classifies a rendered waveform character for styling
+type CellKind int
+
+const (
+	CellNormal    CellKind = iota // Regular 0/1 level, edge or bus value
+	CellUnknown                   // Unknown value (x)
+	CellHighZ                     // High impedance (z)
+	CellGlitch                    // Several changes collapsed into one character
+	CellMarker                    // Marker overlay
+	CellSelection                 // Drag selection overlay
+)
+
+// Cell is a single rendered waveform character and its classification
+type Cell struct {
+	Char string
+	Kind CellKind
+}
+
+// CellsToString joins the characters of a row of cells
+func CellsToString(cells []Cell) string {
+	var sb strings.Builder
+	for _, c := range cells {
+		sb.WriteString(c.Char)
+	}
+	return sb.String()
+}
+
+// cellActivity describes the value changes that fall inside one character cell
+type cellActivity struct {
+	startValue string // Value at the cell start time
+	fromValue  string // Value just before the first change
+	toValue    string // Value after the first change
+	endValue   string // Value after the last change
+	changes    int    // Number of changes inside the cell
+	unknown    bool   // Some value inside the cell has an x
+	highZ      bool   // Some value inside the cell has a z
+}
+
+const (
+	// scanChanges is how many changes of a cell are read one by one; the
+	// rest are taken from the signal's summaries
+	scanChanges = 16
+
+	// denseChanges is the number of changes from which a cell shows dense
+	// activity rather than a glitch
+	denseChanges = 4
+)
+
+// scanCell collects the value changes of a signal inside [cellStart, cellEnd),
+// reading them with a cursor that walks the row's cells left to right.
+// Changes that repeat the current value are not counted.
+func scanCell(sig *waveform.SignalData, cur *waveform.Cursor, cellStart, cellEnd uint64) cellActivity {
+	act := cellActivity{startValue: sig.GetValueAt(cellStart)}
+
+	// Value entering the cell; at time 0 the initial value is not a transition
+	cur.Seek(cellStart)
+	prev := act.startValue
+	if cellStart > 0 {
+		prev = cur.Value()
+	}
+	act.fromValue = prev
+
+	for scanned := 0; ; scanned++ {
+		if scanned == scanChanges {
+			// A zoomed-out cell over many changes
+			act.merge(cur.Summarize(cellEnd))
+			break
+		}
+		change, ok := cur.Next(cellEnd)
+		if !ok {
+			break
+		}
+		if change.Value == prev {
+			continue
+		}
+		if act.changes == 0 {
+			act.toValue = change.Value
+		}
+		act.endValue = change.Value
+		act.changes++
+		act.unknown = act.unknown || valueKind(change.Value) == CellUnknown
+		act.highZ = act.highZ || valueKind(change.Value) == CellHighZ
+		prev = change.Value
+	}
+	return act
+}
+
+// merge adds the summary of the changes that follow those seen so far
+func (act *cellActivity) merge(s waveform.Summary) {
+	if s.Transitions == 0 {
+		return
+	}
+	if act.changes == 0 {
+		act.toValue = s.First
+	}
+	act.endValue = s.Last
+	act.changes += s.Transitions
+	act.unknown = act.unknown || s.Unknown
+	act.highZ = act.highZ || s.HighZ
+}
+
+// dense reports whether the cell has too many changes to show them apart
+func (act cellActivity) dense() bool {
+	return act.changes >= denseChanges
+}
+
+// denseCell returns the cell drawn for dense activity, colored for any
+// unknown or high-Z values it covers
+func (act cellActivity) denseCell() Cell {
+	switch {
+	case act.unknown:
+		return Cell{Char: CharDense, Kind: CellUnknown}
+	case act.highZ:
+		return Cell{Char: CharDense, Kind: CellHighZ}
+	}
+	return Cell{Char: CharDense}
+}
+
+// valueKind classifies a signal value (single bit or bus)
+func valueKind(value string) CellKind {
+	if strings.ContainsAny(value, "xX") {
+		return CellUnknown
+	}
+	if strings.ContainsAny(value, "zZ") {
+		return CellHighZ
+	}
+	return CellNormal
+}
@@ -0,0 +1,95 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+// kinds returns one letter per cell: . normal, x unknown, z high-Z, g glitch
+func kinds(cells []Cell) string {
+	var sb strings.Builder
+	for _, c := range cells {
+		sb.WriteByte(".xzg"[c.Kind])
+	}
+	return sb.String()
+}
+
+func TestRenderWaveformCells(t *testing.T) {
+	tests := []struct {
+		name    string
+		width   int
+		changes []waveform.ValueChange
+		chars   string
+		kinds   string
+	}{
+		{
+			name:    "edges",
+			width:   1,
+			changes: []waveform.ValueChange{{Time: 0, Value: "0"}, {Time: 2, Value: "1"}, {Time: 5, Value: "0"}},
+			chars:   "__/‾‾\\__",
+			kinds:   "........",
+		},
+		{
+			name:    "unknown and high-Z",
+			width:   1,
+			changes: []waveform.ValueChange{{Time: 0, Value: "x"}, {Time: 2, Value: "1"}, {Time: 4, Value: "z"}, {Time: 6, Value: "0"}},
+			chars:   "???‾ZZ?_",
+			kinds:   "xxx.zzx.",
+		},
+		{
+			name:    "glitch",
+			width:   1,
+			changes: []waveform.ValueChange{{Time: 0, Value: "0"}, {Time: 3, Value: "1"}, {Time: 3, Value: "0"}, {Time: 4, Value: "1"}, {Time: 4, Value: "1"}},
+			chars:   "___╫/‾‾‾",
+			kinds:   "...g....",
+		},
+		{
+			name:    "bus values",
+			width:   8,
+			changes: []waveform.ValueChange{{Time: 0, Value: "1010"}, {Time: 4, Value: "x"}},
+			chars:   "-0A-XXX-",
+			kinds:   ".....xxx",
+		},
+		{
+			name:    "bus glitch",
+			width:   8,
+			changes: []waveform.ValueChange{{Time: 0, Value: "0"}, {Time: 4, Value: "1"}, {Time: 4, Value: "10"}},
+			chars:   "-00-╫02-",
+			kinds:   "....g...",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig := &waveform.SignalData{Signal: waveform.Signal{Name: "s", Width: tt.width}, Changes: tt.changes}
+			cells := RenderWaveformCells(sig, 0, 8, 8, RadixHex)
+			if got := CellsToString(cells); got != tt.chars {
+				t.Errorf("chars %q, want %q", got, tt.chars)
+			}
+			if got := kinds(cells); got != tt.kinds {
+				t.Errorf("kinds %q, want %q", got, tt.kinds)
+			}
+		})
+	}
+}
+
+func TestValueKind(t *testing.T) {
+	tests := []struct {
+		value string
+		want  CellKind
+	}{
+		{"0", CellNormal},
+		{"1010", CellNormal},
+		{"x", CellUnknown},
+		{"10X1", CellUnknown},
+		{"z", CellHighZ},
+		{"zzZ0", CellHighZ},
+		{"xz", CellUnknown},
+	}
+	for _, tt := range tests {
+		if got := valueKind(tt.value); got != tt.want {
+			t.Errorf("valueKind(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
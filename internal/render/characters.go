@@ -10,13 +10,25 @@ const (
 	CharFallingEdge = "\\" // Falling edge (1→0)
 	CharUnknown     = "?"  // Unknown value
 	CharHighZ       = "Z"  // High impedance
+	CharGlitch      = "╫"  // Several changes within one character
+	CharDense       = "▒"  // Dense activity: many changes within one character
 
 	// Bus signal characters
-	CharBusRise   = "X"  // Bus transition marker (single cell)
-	CharBusFall   = "X"  // Kept for compatibility; not used when rendering single-cell markers
-	CharBusHigh   = "▔"  // Bus top line
-	CharBusLow    = "▁"  // Bus bottom line
-	CharBusMiddle = " "  // Bus middle (for value display)
+	CharBusRise   = "X" // Bus transition marker (single cell)
+	CharBusHigh   = "▔" // Bus top line
+	CharBusLow    = "▁" // Bus bottom line
+	CharBusMiddle = " " // Bus middle (for value display)
+
+	// Multi-row (tall) waveform characters
+	CharTallRail       = "─" // High/low rail, bus outline
+	CharTallRiseTop    = "┌" // Rising edge, top rail
+	CharTallRiseBottom = "┘" // Rising edge, bottom rail
+	CharTallFallTop    = "┐" // Falling edge, top rail
+	CharTallFallBottom = "└" // Falling edge, bottom rail
+	CharTallEdge       = "│" // Other transitions
+	CharTallUnknown    = "░" // Unknown value (x)
+	CharTallHighZ      = "╌" // High impedance
+	CharTallBusCross   = "╳" // Bus value transition
 
 	// Cursor
 	CharCursor = "│"
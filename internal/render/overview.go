@@ -0,0 +1,59 @@
+package render
+
+import (
+	"sort"
+
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+// densityChars are the overview heatmap levels, from no activity to the busiest column
+var densityChars = []string{" ", "░", "▒", "▓", "█"}
+
+// ActivityDensity counts the value changes of a signal in each of width
+// columns spanning [startTime, endTime]. The initial value is not counted.
+func ActivityDensity(sig *waveform.SignalData, startTime, endTime uint64, width int) []int {
+	counts := make([]int, width)
+	if sig == nil || width <= 0 {
+		return counts
+	}
+	changes := sig.ChangesIn(startTime, endTime)
+	if len(changes) > 0 && changes[0].Time == 0 {
+		changes = changes[1:]
+	}
+
+	// Each column's changes start at the first one placed in it or further
+	// right; the columns are found by binary search, not by visiting every
+	// change of a zoomed-out dump
+	column := func(t uint64) int {
+		pos, _ := RenderCursor(t, startTime, endTime, width)
+		return pos
+	}
+	from := 0
+	for pos := 0; pos < width; pos++ {
+		to := from + sort.Search(len(changes)-from, func(i int) bool {
+			return column(changes[from+i].Time) > pos
+		})
+		counts[pos] = to - from
+		from = to
+	}
+	return counts
+}
+
+// DensityCells renders change counts as heatmap cells scaled to the busiest column
+func DensityCells(counts []int) []Cell {
+	busiest := 0
+	for _, c := range counts {
+		busiest = max(busiest, c)
+	}
+
+	cells := make([]Cell, len(counts))
+	for i, c := range counts {
+		level := 0
+		if c > 0 {
+			// Any activity shows at least the lightest shade
+			level = 1 + (c-1)*(len(densityChars)-2)/max(busiest-1, 1)
+		}
+		cells[i] = Cell{Char: densityChars[level]}
+	}
+	return cells
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+func TestActivityDensity(t *testing.T) {
+	sig := &waveform.SignalData{Changes: []waveform.ValueChange{
+		{Time: 0, Value: "0"}, // Initial value, not counted
+		{Time: 5, Value: "1"},
+		{Time: 10, Value: "0"},
+		{Time: 15, Value: "1"},
+		{Time: 79, Value: "0"},
+		{Time: 100, Value: "1"}, // Past the end
+	}}
+	tests := []struct {
+		name       string
+		start, end uint64
+		width      int
+		want       []int
+	}{
+		{"whole dump", 0, 80, 4, []int{3, 0, 0, 1}},
+		{"window", 10, 30, 2, []int{2, 0}},
+		{"one column", 0, 80, 1, []int{4}},
+		{"no width", 0, 80, 0, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ActivityDensity(sig, tt.start, tt.end, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("counts %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := ActivityDensity(nil, 0, 80, 3); !reflect.DeepEqual(got, []int{0, 0, 0}) {
+		t.Errorf("no signal: counts %v", got)
+	}
+}
+
+func TestDensityCells(t *testing.T) {
+	tests := []struct {
+		counts []int
+		want   string
+	}{
+		{[]int{0, 1, 2, 3, 4, 5}, " ░░▒▓█"},
+		{[]int{0, 1, 0, 100}, " ░ █"},
+		{[]int{7, 7}, "██"}, // The busiest columns are full
+		{[]int{0, 0}, "  "},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := CellsToString(DensityCells(tt.counts)); got != tt.want {
+			t.Errorf("DensityCells(%v) = %q, want %q", tt.counts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package render
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+// Radix selects how bus values are displayed
+type Radix string
+
+const (
+	RadixHex Radix = "hex"
+	RadixBin Radix = "bin"
+	RadixDec Radix = "dec"
+)
+
+// Radixes lists the display radixes in cycling order
+var Radixes = []Radix{RadixHex, RadixBin, RadixDec}
+
+// ParseRadix converts a radix name to a Radix, defaulting to hex
+func ParseRadix(name string) (Radix, bool) {
+	for _, r := range Radixes {
+		if string(r) == strings.ToLower(name) {
+			return r, true
+		}
+	}
+	return RadixHex, false
+}
+
+// NextRadix returns the radix following r in cycling order
+func NextRadix(r Radix) Radix {
+	for i, candidate := range Radixes {
+		if candidate == r {
+			return Radixes[(i+1)%len(Radixes)]
+		}
+	}
+	return RadixHex
+}
+
+// FormatBusValue formats a binary bus value in the given radix
+func FormatBusValue(binary string, width int, radix Radix) string {
+	switch radix {
+	case RadixBin:
+		return strings.ToLower(waveform.ExtendValue(binary, width))
+	case RadixDec:
+		if strings.ContainsAny(binary, "xX") {
+			return "X"
+		}
+		if strings.ContainsAny(binary, "zZ") {
+			return "Z"
+		}
+		n, ok := new(big.Int).SetString(binary, 2)
+		if !ok {
+			return "?"
+		}
+		return n.String()
+	default:
+		return binaryToHex(binary, width)
+	}
+}
@@ -0,0 +1,67 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatBusValue(t *testing.T) {
+	wide := "1" + strings.Repeat("0", 80) // 2^80
+	tests := []struct {
+		binary string
+		width  int
+		radix  Radix
+		want   string
+	}{
+		{"1010", 4, RadixHex, "A"},
+		{"101", 8, RadixHex, "05"},
+		{"x1", 8, RadixHex, "XX"},
+		{"z", 8, RadixHex, "ZZ"},
+		{wide, 81, RadixHex, "100000000000000000000"},
+		{"1" + strings.Repeat("0", 63), 128, RadixHex, "00000000000000008000000000000000"},
+		{"101", 8, RadixBin, "00000101"},
+		{"X1", 4, RadixBin, "xxx1"},
+		{"Z", 2, RadixBin, "zz"},
+		{"11111111", 8, RadixDec, "255"},
+		{"0", 8, RadixDec, "0"},
+		{wide, 81, RadixDec, "1208925819614629174706176"},
+		{"1x", 8, RadixDec, "X"},
+		{"1z", 8, RadixDec, "Z"},
+	}
+	for _, tt := range tests {
+		if got := FormatBusValue(tt.binary, tt.width, tt.radix); got != tt.want {
+			t.Errorf("FormatBusValue(%q, %d, %s) = %q, want %q", tt.binary, tt.width, tt.radix, got, tt.want)
+		}
+	}
+}
+
+func TestParseRadix(t *testing.T) {
+	tests := []struct {
+		name string
+		want Radix
+		ok   bool
+	}{
+		{"hex", RadixHex, true},
+		{"BIN", RadixBin, true},
+		{"dec", RadixDec, true},
+		{"oct", RadixHex, false},
+		{"", RadixHex, false},
+	}
+	for _, tt := range tests {
+		if got, ok := ParseRadix(tt.name); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseRadix(%q) = %s, %v, want %s, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	// Cycling visits every radix once
+	r := RadixHex
+	for i := range Radixes {
+		if r != Radixes[i] {
+			t.Errorf("cycle step %d: %s, want %s", i, r, Radixes[i])
+		}
+		r = NextRadix(r)
+	}
+	if r != RadixHex {
+		t.Errorf("cycle ends at %s", r)
+	}
+}
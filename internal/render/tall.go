@@ -0,0 +1,133 @@
+package render
+
+import (
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+// Row counts used by the multi-row (tall) rendering mode
+const (
+	TallSingleBitRows = 2 // top rail, bottom rail
+	TallBusRows       = 3 // top rail, value row, bottom rail
+)
+
+// TallRowCount returns the number of terminal rows a signal occupies in tall mode
+func TallRowCount(sig *waveform.SignalData) int {
+	if sig.Signal.Width == 1 {
+		return TallSingleBitRows
+	}
+	return TallBusRows
+}
+
+// RenderWaveformTall renders a signal's waveform in multi-row mode.
+// Single-bit signals use two rows (high and low rail), buses use three
+// rows (top rail, value row, bottom rail).
+func RenderWaveformTall(sig *waveform.SignalData, startTime, endTime uint64, width int, radix Radix) []string {
+	cells := RenderWaveformTallCells(sig, startTime, endTime, width, radix)
+	lines := make([]string, len(cells))
+	for r := range cells {
+		lines[r] = CellsToString(cells[r])
+	}
+	return lines
+}
+
+// RenderWaveformTallCells renders a signal's waveform in multi-row mode as classified cells
+func RenderWaveformTallCells(sig *waveform.SignalData, startTime, endTime uint64, width int, radix Radix) [][]Cell {
+	rows := TallRowCount(sig)
+	result := make([][]Cell, rows)
+	if width <= 0 || endTime <= startTime {
+		return result
+	}
+
+	timePerChar := float64(endTime-startTime) / float64(width)
+	for r := range result {
+		result[r] = make([]Cell, width)
+	}
+
+	if sig.Signal.Width == 1 {
+		renderSingleBitTall(sig, startTime, timePerChar, result[0], result[1])
+	} else {
+		renderBusTall(sig, startTime, timePerChar, result[0], result[1], result[2], width, radix)
+	}
+
+	return result
+}
+
+// renderSingleBitTall renders a single-bit signal onto a top and bottom rail
+func renderSingleBitTall(sig *waveform.SignalData, startTime uint64, timePerChar float64, top, bottom []Cell) {
+	set := func(i int, topChar, bottomChar string, kind CellKind) {
+		top[i] = Cell{Char: topChar, Kind: kind}
+		bottom[i] = Cell{Char: bottomChar, Kind: kind}
+	}
+
+	cur := sig.Cursor(startTime)
+	for i := range top {
+		charStartTime := startTime + uint64(float64(i)*timePerChar)
+		charEndTime := startTime + uint64(float64(i+1)*timePerChar)
+
+		act := scanCell(sig, cur, charStartTime, charEndTime)
+
+		if act.dense() {
+			dense := act.denseCell()
+			set(i, dense.Char, dense.Char, dense.Kind)
+			continue
+		}
+		if act.changes > 1 {
+			set(i, CharGlitch, CharGlitch, CellGlitch)
+			continue
+		}
+
+		if act.changes == 1 {
+			switch {
+			case act.fromValue == "0" && act.toValue == "1":
+				set(i, CharTallRiseTop, CharTallRiseBottom, CellNormal)
+			case act.fromValue == "1" && act.toValue == "0":
+				set(i, CharTallFallTop, CharTallFallBottom, CellNormal)
+			case valueKind(act.toValue) == CellHighZ:
+				set(i, CharTallHighZ, CharTallHighZ, CellHighZ)
+			case valueKind(act.toValue) == CellUnknown:
+				set(i, CharTallUnknown, CharTallUnknown, CellUnknown)
+			default:
+				set(i, CharTallEdge, CharTallEdge, CellNormal)
+			}
+			continue
+		}
+
+		switch act.startValue {
+		case "1":
+			set(i, CharTallRail, " ", CellNormal)
+		case "0":
+			set(i, " ", CharTallRail, CellNormal)
+		case "z", "Z":
+			set(i, CharTallHighZ, CharTallHighZ, CellHighZ)
+		default:
+			set(i, CharTallUnknown, CharTallUnknown, CellUnknown)
+		}
+	}
+}
+
+// renderBusTall renders a bus signal with rails above and below the value row
+func renderBusTall(sig *waveform.SignalData, startTime uint64, timePerChar float64, top, middle, bottom []Cell, width int, radix Radix) {
+	for i := 0; i < width; i++ {
+		top[i] = Cell{Char: CharTallRail}
+		middle[i] = Cell{Char: " "}
+		bottom[i] = Cell{Char: CharTallRail}
+	}
+
+	for _, seg := range busSegments(sig, startTime, timePerChar, width) {
+		kind := valueKind(seg.value)
+		for i := seg.startIdx; i < seg.endIdx; i++ {
+			top[i].Kind = kind
+			bottom[i].Kind = kind
+		}
+
+		valueStart := seg.startIdx
+		if seg.startIdx > 0 {
+			// Transition cell: open the rails and draw a crossing
+			top[seg.startIdx] = Cell{Char: " "}
+			middle[seg.startIdx] = transitionCell(seg, CharTallBusCross)
+			bottom[seg.startIdx] = Cell{Char: " "}
+			valueStart++
+		}
+		placeValue(middle, FormatBusValue(seg.value, sig.Signal.Width, radix), valueStart, seg.endIdx, " ", kind)
+	}
+}
@@ -2,179 +2,185 @@ package render
 
 import (
 	"fmt"
-	"strconv"
+	"math/big"
 	"strings"
 
-	"sigscope/internal/vcd"
+	"github.com/hitsan/sigscope/pkg/waveform"
 )
 
 // RenderWaveformSingleLine renders a signal's waveform in single-line mode
-func RenderWaveformSingleLine(sig *vcd.SignalData, startTime, endTime uint64, width int) string {
+func RenderWaveformSingleLine(sig *waveform.SignalData, startTime, endTime uint64, width int, radix Radix) string {
+	return CellsToString(RenderWaveformCells(sig, startTime, endTime, width, radix))
+}
+
+// RenderWaveformCells renders a signal's waveform in single-line mode as classified cells
+func RenderWaveformCells(sig *waveform.SignalData, startTime, endTime uint64, width int, radix Radix) []Cell {
 	if width <= 0 || endTime <= startTime {
-		return ""
+		return nil
 	}
 
 	timePerChar := float64(endTime-startTime) / float64(width)
-	result := make([]string, width)
+	result := make([]Cell, width)
 
 	if sig.Signal.Width == 1 {
 		renderSingleBitOneLine(sig, startTime, timePerChar, result)
 	} else {
-		renderBusOneLine(sig, startTime, timePerChar, result, width)
+		renderBusOneLine(sig, startTime, timePerChar, result, width, radix)
 	}
 
-	return strings.Join(result, "")
+	return result
 }
 
 // renderSingleBitOneLine renders a single-bit signal in single-line mode
-func renderSingleBitOneLine(sig *vcd.SignalData, startTime uint64, timePerChar float64, result []string) {
+func renderSingleBitOneLine(sig *waveform.SignalData, startTime uint64, timePerChar float64, result []Cell) {
+	cur := sig.Cursor(startTime)
 	for i := range result {
 		charStartTime := startTime + uint64(float64(i)*timePerChar)
 		charEndTime := startTime + uint64(float64(i+1)*timePerChar)
 
-		// Use the value at the start of this cell
-		startValue := sig.GetValueAt(charStartTime)
-
 		// Check for transitions within this character
-		var transitionTo string
-		hasTransition := false
-		for _, change := range sig.Changes {
-			if change.Time >= charStartTime && change.Time < charEndTime {
-				hasTransition = true
-				transitionTo = change.Value
-				break
-			}
-		}
-
-		if hasTransition {
+		act := scanCell(sig, cur, charStartTime, charEndTime)
+
+		if act.dense() {
+			result[i] = act.denseCell()
+		} else if act.changes > 1 {
+			// Several changes collapsed into one character
+			result[i] = Cell{Char: CharGlitch, Kind: CellGlitch}
+		} else if act.changes == 1 {
 			// Determine transition direction
-			if startValue == "0" && transitionTo == "1" {
-				result[i] = CharRisingEdge // /
-			} else if startValue == "1" && transitionTo == "0" {
-				result[i] = CharFallingEdge // \
+			if act.fromValue == "0" && act.toValue == "1" {
+				result[i] = Cell{Char: CharRisingEdge} // /
+			} else if act.fromValue == "1" && act.toValue == "0" {
+				result[i] = Cell{Char: CharFallingEdge} // \
+			} else if valueKind(act.toValue) == CellHighZ {
+				result[i] = Cell{Char: CharHighZ, Kind: CellHighZ}
 			} else {
-				result[i] = CharUnknown // ?
+				result[i] = Cell{Char: CharUnknown, Kind: CellUnknown} // ?
 			}
 		} else {
 			// Stable state
-			switch startValue {
+			switch act.startValue {
 			case "1":
-				result[i] = CharHigh // ‾
+				result[i] = Cell{Char: CharHigh} // ‾
 			case "0":
-				result[i] = CharLow // _
-			case "x", "X":
-				result[i] = CharUnknown // ?
+				result[i] = Cell{Char: CharLow} // _
 			case "z", "Z":
-				result[i] = CharHighZ // ~
+				result[i] = Cell{Char: CharHighZ, Kind: CellHighZ} // Z
 			default:
-				result[i] = CharUnknown
+				result[i] = Cell{Char: CharUnknown, Kind: CellUnknown} // ?
 			}
 		}
 	}
 }
 
-// renderBusOneLine renders a multi-bit bus signal in single-line mode
-func renderBusOneLine(sig *vcd.SignalData, startTime uint64, timePerChar float64, result []string, width int) {
-	// Find all value changes in the visible window
-	type segment struct {
-		startIdx int
-		endIdx   int
-		value    string
-	}
+// busSegment is a run of character cells holding a single bus value
+type busSegment struct {
+	startIdx   int
+	endIdx     int
+	value      string
+	transition cellActivity // Changes inside the cell where the segment begins
+}
 
-	segments := make([]segment, 0)
-	currentValue := sig.GetValueAt(startTime)
-	currentStartIdx := 0
+// busSegments splits the visible window of a bus signal into value segments
+func busSegments(sig *waveform.SignalData, startTime uint64, timePerChar float64, width int) []busSegment {
+	segments := make([]busSegment, 0)
+	current := busSegment{value: sig.GetValueAt(startTime)}
+	cur := sig.Cursor(startTime)
 
 	for i := 0; i < width; i++ {
 		charTime := startTime + uint64(float64(i)*timePerChar)
 		charEndTime := startTime + uint64(float64(i+1)*timePerChar)
 
 		// Check for value change in this character
-		for _, change := range sig.Changes {
-			if change.Time >= charTime && change.Time < charEndTime {
-				// End current segment
-				if i > currentStartIdx {
-					segments = append(segments, segment{
-						startIdx: currentStartIdx,
-						endIdx:   i,
-						value:    currentValue,
-					})
-				}
-				currentValue = change.Value
-				currentStartIdx = i
-				break
+		act := scanCell(sig, cur, charTime, charEndTime)
+		if act.changes > 0 {
+			// End current segment
+			if i > current.startIdx {
+				current.endIdx = i
+				segments = append(segments, current)
 			}
+			current = busSegment{startIdx: i, value: act.endValue, transition: act}
 		}
 	}
 
 	// Add final segment
-	if currentStartIdx < width {
-		segments = append(segments, segment{
-			startIdx: currentStartIdx,
-			endIdx:   width,
-			value:    currentValue,
-		})
+	if current.startIdx < width {
+		current.endIdx = width
+		segments = append(segments, current)
 	}
 
+	return segments
+}
+
+// renderBusOneLine renders a multi-bit bus signal in single-line mode
+func renderBusOneLine(sig *waveform.SignalData, startTime uint64, timePerChar float64, result []Cell, width int, radix Radix) {
+	segments := busSegments(sig, startTime, timePerChar, width)
+
 	// Initialize with spaces
 	for i := range result {
-		result[i] = " "
+		result[i] = Cell{Char: " "}
 	}
 
 	// Render each segment
 	for _, seg := range segments {
 		segWidth := seg.endIdx - seg.startIdx
+		kind := valueKind(seg.value)
 
-		// Convert binary value to hex
-		hexValue := binaryToHex(seg.value, sig.Signal.Width)
+		// Convert binary value to the display radix
+		displayValue := FormatBusValue(seg.value, sig.Signal.Width, radix)
+
+		// Show transition at start
+		valueStart := seg.startIdx
+		if seg.startIdx > 0 {
+			result[seg.startIdx] = transitionCell(seg, CharBusRise)
+			valueStart = seg.startIdx + 1
+		}
 
 		if segWidth <= 2 {
 			// Too narrow for value, just show transitions
-			if seg.startIdx > 0 {
-				result[seg.startIdx] = CharBusRise
-			}
 			for i := seg.startIdx + 1; i < seg.endIdx; i++ {
-				result[i] = "="
+				result[i] = Cell{Char: "=", Kind: kind}
 			}
 		} else {
-			// Show transition at start
-			if seg.startIdx > 0 {
-				result[seg.startIdx] = CharBusRise
-			}
-
-			// Calculate space for value
-			valueStart := seg.startIdx
-			if seg.startIdx > 0 {
-				valueStart = seg.startIdx + 1
-			}
-			valueEnd := seg.endIdx
-
-			// Center the hex value
-			availableWidth := valueEnd - valueStart
-			if availableWidth > 0 {
-				displayValue := hexValue
-				if len(displayValue) > availableWidth {
-					displayValue = displayValue[:availableWidth]
-				}
-
-				// Fill with '=' first
-				padding := (availableWidth - len(displayValue)) / 2
-				for i := valueStart; i < valueEnd; i++ {
-					result[i] = "-"
-				}
-				// Overwrite with hex value
-				for idx, ch := range displayValue {
-					pos := valueStart + padding + idx
-					if pos < valueEnd {
-						result[pos] = string(ch)
-					}
-				}
-			}
+			placeValue(result, displayValue, valueStart, seg.endIdx, "-", kind)
 		}
 	}
 }
 
+// transitionCell returns the cell drawn where a bus segment begins
+func transitionCell(seg busSegment, char string) Cell {
+	if seg.transition.dense() {
+		return seg.transition.denseCell()
+	}
+	if seg.transition.changes > 1 {
+		return Cell{Char: CharGlitch, Kind: CellGlitch}
+	}
+	return Cell{Char: char}
+}
+
+// placeValue centers a value label in result[start:end], filling the rest with fill
+func placeValue(result []Cell, value string, start, end int, fill string, kind CellKind) {
+	availableWidth := end - start
+	if availableWidth <= 0 {
+		return
+	}
+
+	displayValue := value
+	if len(displayValue) > availableWidth {
+		displayValue = displayValue[:availableWidth]
+	}
+
+	padding := (availableWidth - len(displayValue)) / 2
+	for i := start; i < end; i++ {
+		result[i] = Cell{Char: fill, Kind: kind}
+	}
+	for idx, ch := range displayValue {
+		pos := start + padding + idx
+		if pos < end {
+			result[pos] = Cell{Char: string(ch), Kind: kind}
+		}
+	}
+}
 
 // binaryToHex converts a binary string to hexadecimal
 func binaryToHex(binary string, width int) string {
@@ -186,23 +192,15 @@ func binaryToHex(binary string, width int) string {
 		return "ZZ"
 	}
 
-	// Pad binary to multiple of 4
-	padded := binary
-	remainder := len(binary) % 4
-	if remainder != 0 {
-		padded = strings.Repeat("0", 4-remainder) + binary
-	}
-
-	// Convert to hex
-	val, err := strconv.ParseUint(padded, 2, 64)
-	if err != nil {
+	// Convert to hex, through a big.Int for buses wider than 64 bits
+	val, ok := new(big.Int).SetString(binary, 2)
+	if !ok {
 		return "??"
 	}
 
 	// Format with appropriate width
 	hexWidth := (width + 3) / 4
-	format := fmt.Sprintf("%%0%dX", hexWidth)
-	return fmt.Sprintf(format, val)
+	return fmt.Sprintf("%0*X", hexWidth, val)
 }
 
 // RenderCursor returns a cursor marker at the given position
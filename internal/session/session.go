@@ -0,0 +1,222 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hitsan/sigscope/internal/model"
+	"github.com/hitsan/sigscope/internal/render"
+)
+
+// CurrentVersion is the session file format version written by Save
+const CurrentVersion = 1
+
+// Session is the on-disk form of a curated TUI view
+type Session struct {
+	Version       int          `json:"version"`
+	Dump          string       `json:"dump,omitempty"`   // VCD file the session was saved from
+	Signals       []Signal     `json:"signals"`          // Visible signals in display order
+	Layout        []Entry      `json:"layout,omitempty"` // Display order with dividers and groups
+	ExpandedBuses []string     `json:"expanded_buses,omitempty"`
+	VirtualBuses  []VirtualBus `json:"virtual_buses,omitempty"`
+	Markers       []Marker     `json:"markers,omitempty"`
+	Cursor        uint64       `json:"cursor"`
+	TimeStart     uint64       `json:"time_start"`
+	TimeEnd       uint64       `json:"time_end"`
+	Zoom          float64      `json:"zoom"`
+	Selected      string       `json:"selected,omitempty"`
+	Tall          bool         `json:"tall,omitempty"`
+}
+
+// Signal is a visible signal, referenced by its full hierarchical name
+type Signal struct {
+	Name  string `json:"name"`
+	Radix string `json:"radix,omitempty"` // "hex", "bin" or "dec" for buses
+	Color string `json:"color,omitempty"` // ANSI color (0-255) or "#rrggbb"
+}
+
+// Entry is a node of the display layout
+type Entry struct {
+	Type      string  `json:"type"` // "signal", "divider" or "group"
+	Name      string  `json:"name"`
+	Collapsed bool    `json:"collapsed,omitempty"`
+	Children  []Entry `json:"children,omitempty"`
+}
+
+// Layout entry types
+const (
+	EntrySignal  = "signal"
+	EntryDivider = "divider"
+	EntryGroup   = "group"
+)
+
+// VirtualBus is a user-defined bus built from 1-bit signals
+type VirtualBus struct {
+	Name string   `json:"name"`
+	Bits []string `json:"bits"` // MSB first
+}
+
+// Marker is a named time marker
+type Marker struct {
+	Name string `json:"name"`
+	Time uint64 `json:"time"`
+}
+
+// Load reads a session file
+func Load(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid session file %s: %w", path, err)
+	}
+	if s.Version > CurrentVersion {
+		return nil, fmt.Errorf("session file %s has unsupported version %d", path, s.Version)
+	}
+	return &s, nil
+}
+
+// Save writes a session file
+func Save(path string, s *Session) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// FromModel captures the current TUI view as a session
+func FromModel(m model.Model) *Session {
+	state := m.CaptureViewState()
+	s := &Session{
+		Version:       CurrentVersion,
+		Dump:          m.Filename,
+		ExpandedBuses: state.ExpandedBuses,
+		Cursor:        state.CursorTime,
+		TimeStart:     state.TimeStart,
+		TimeEnd:       state.TimeEnd,
+		Zoom:          state.Zoom,
+		Selected:      state.SelectedName,
+		Tall:          state.TallMode,
+	}
+
+	for _, idx := range m.OrderedSignalIndices() {
+		if !m.SignalVisible[idx] {
+			continue
+		}
+		name := m.Signals[idx].Signal.FullName
+		entry := Signal{Name: name}
+		if r, ok := state.Radix[name]; ok {
+			entry.Radix = string(r)
+		}
+		entry.Color = state.Colors[name]
+		s.Signals = append(s.Signals, entry)
+	}
+	for _, vb := range state.VirtualBuses {
+		s.VirtualBuses = append(s.VirtualBuses, VirtualBus{Name: vb.Name, Bits: vb.Bits})
+	}
+	for _, mk := range state.Markers {
+		s.Markers = append(s.Markers, Marker{Name: mk.Name, Time: mk.Time})
+	}
+	s.Layout = layoutFromModel(m, m.Layout)
+	return s
+}
+
+// layoutFromModel converts model layout entries, leaving out hidden signals
+func layoutFromModel(m model.Model, entries []*model.Entry) []Entry {
+	var layout []Entry
+	for _, e := range entries {
+		switch e.Kind {
+		case model.RowSignal:
+			if m.EntryVisible(e) {
+				layout = append(layout, Entry{Type: EntrySignal, Name: e.Name})
+			}
+		case model.RowDivider:
+			layout = append(layout, Entry{Type: EntryDivider, Name: e.Name})
+		case model.RowGroup:
+			layout = append(layout, Entry{
+				Type:      EntryGroup,
+				Name:      e.Name,
+				Collapsed: e.Collapsed,
+				Children:  layoutFromModel(m, e.Children),
+			})
+		}
+	}
+	return layout
+}
+
+// toModelLayout converts session layout entries to model entries
+func toModelLayout(layout []Entry) []*model.Entry {
+	entries := make([]*model.Entry, 0, len(layout))
+	for _, e := range layout {
+		switch e.Type {
+		case EntrySignal:
+			entries = append(entries, &model.Entry{Kind: model.RowSignal, Name: e.Name})
+		case EntryDivider:
+			entries = append(entries, &model.Entry{Kind: model.RowDivider, Name: e.Name})
+		case EntryGroup:
+			entries = append(entries, &model.Entry{
+				Kind:      model.RowGroup,
+				Name:      e.Name,
+				Collapsed: e.Collapsed,
+				Children:  toModelLayout(e.Children),
+			})
+		}
+	}
+	return entries
+}
+
+// ViewState converts the session to a model.ViewState. Signals that are
+// not listed in the session are hidden. Without a layout the signals are
+// ordered as listed.
+func (s *Session) ViewState() model.ViewState {
+	state := model.ViewState{
+		CursorTime:    s.Cursor,
+		TimeStart:     s.TimeStart,
+		TimeEnd:       s.TimeEnd,
+		Zoom:          s.Zoom,
+		ExpandedBuses: s.ExpandedBuses,
+		SelectedName:  s.Selected,
+		HideUnlisted:  true,
+		Radix:         make(map[string]render.Radix),
+		Colors:        make(map[string]string),
+		TallMode:      s.Tall,
+	}
+
+	layout := s.Layout
+	for _, sig := range s.Signals {
+		if s.Layout == nil {
+			layout = append(layout, Entry{Type: EntrySignal, Name: sig.Name})
+		}
+		state.SignalNames = append(state.SignalNames, sig.Name)
+		state.SignalVisible = append(state.SignalVisible, true)
+		if r, ok := render.ParseRadix(sig.Radix); ok {
+			state.Radix[sig.Name] = r
+		}
+		if c, err := model.ParseColor(sig.Color); err == nil {
+			state.Colors[sig.Name] = c
+		}
+	}
+	for _, vb := range s.VirtualBuses {
+		state.VirtualBuses = append(state.VirtualBuses, model.VirtualBus{Name: vb.Name, Bits: vb.Bits})
+	}
+	for _, mk := range s.Markers {
+		state.Markers = append(state.Markers, model.Marker{Name: mk.Name, Time: mk.Time})
+	}
+	state.Layout = toModelLayout(layout)
+	return state
+}
+
+// Apply restores the session onto a model
+func (s *Session) Apply(m *model.Model) {
+	m.RestoreViewState(s.ViewState())
+}
+
+// DefaultPath returns the session path used when none was given
+func DefaultPath(vcdFile string) string {
+	return vcdFile + ".session.json"
+}
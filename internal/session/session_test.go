@@ -0,0 +1,166 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hitsan/sigscope/internal/model"
+	"github.com/hitsan/sigscope/pkg/waveform"
+)
+
+const testDump = `$timescale 1ns $end
+$scope module top $end
+$var wire 1 ! clk $end
+$var wire 1 # valid $end
+$var wire 4 " data [3:0] $end
+$var wire 8 $ addr [7:0] $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+0#
+b0 "
+b0 $
+#100
+1!
+`
+
+func testModel(t *testing.T) model.Model {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sim.vcd")
+	if err := os.WriteFile(path, []byte(testDump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := waveform.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := model.NewModel(v, "sim.vcd")
+	m.Width, m.Height = 120, 40
+	return m
+}
+
+func TestSaveLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Session
+	}{
+		{"minimal", Session{Version: CurrentVersion, Signals: []Signal{{Name: "top.clk"}}}},
+		{"everything", Session{
+			Version:       CurrentVersion,
+			Dump:          "sim.vcd",
+			Signals:       []Signal{{Name: "top.clk", Color: "#ff8800"}, {Name: "top.data", Radix: "dec"}},
+			Layout:        []Entry{{Type: EntryDivider, Name: "bus"}, {Type: EntryGroup, Name: "g", Collapsed: true, Children: []Entry{{Type: EntrySignal, Name: "top.data"}}}},
+			ExpandedBuses: []string{"top.data"},
+			VirtualBuses:  []VirtualBus{{Name: "vb", Bits: []string{"top.clk", "top.valid"}}},
+			Markers:       []Marker{{Name: "A", Time: 10}},
+			Cursor:        40,
+			TimeStart:     10,
+			TimeEnd:       90,
+			Zoom:          1.25,
+			Selected:      "top.data",
+			Tall:          true,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "s.json")
+			if err := Save(path, &tt.s); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.s) {
+				t.Errorf("loaded %+v, want %+v", *got, tt.s)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"not JSON", "signals: clk", "invalid session file"},
+		{"wrong type", `{"version": "1"}`, "invalid session file"},
+		{"newer version", `{"version": 2}`, "unsupported version 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "s.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v, want one with %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("missing file: error %v, want not exist", err)
+	}
+}
+
+func TestApplyFromModel(t *testing.T) {
+	s := &Session{
+		Version:   CurrentVersion,
+		Dump:      "sim.vcd",
+		Signals:   []Signal{{Name: "top.data", Radix: "bin", Color: "#00ff00"}, {Name: "top.clk"}},
+		Layout:    []Entry{{Type: EntrySignal, Name: "top.data"}, {Type: EntryDivider, Name: "ctl"}, {Type: EntrySignal, Name: "top.clk"}},
+		Markers:   []Marker{{Name: "A", Time: 30}},
+		Cursor:    50,
+		TimeStart: 25,
+		TimeEnd:   75,
+		Zoom:      2, // The dump's length over the window's
+		Selected:  "top.clk",
+	}
+	m := testModel(t)
+	s.Apply(&m)
+
+	// Unlisted signals are hidden
+	var visible []string
+	for _, idx := range m.OrderedSignalIndices() {
+		if m.SignalVisible[idx] {
+			visible = append(visible, m.Signals[idx].Signal.FullName)
+		}
+	}
+	if want := []string{"top.data", "top.clk"}; !reflect.DeepEqual(visible, want) {
+		t.Errorf("visible signals %v, want %v", visible, want)
+	}
+
+	// Compared as saved, where empty and missing lists are the same
+	got, _ := json.Marshal(FromModel(m))
+	want, _ := json.Marshal(s)
+	if string(got) != string(want) {
+		t.Errorf("saved again as\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestViewStateWithoutLayout(t *testing.T) {
+	s := &Session{Signals: []Signal{{Name: "top.valid", Radix: "nope", Color: "bad"}, {Name: "top.addr", Radix: "dec"}}}
+	state := s.ViewState()
+
+	var order []string
+	for _, e := range state.Layout {
+		order = append(order, e.Name)
+	}
+	if want := []string{"top.valid", "top.addr"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("layout %v, want %v", order, want)
+	}
+	if len(state.Radix) != 1 || state.Radix["top.addr"] != "dec" {
+		t.Errorf("radixes %v, want only top.addr dec", state.Radix)
+	}
+	if len(state.Colors) != 0 {
+		t.Errorf("colors %v, want none", state.Colors)
+	}
+}
@@ -0,0 +1,307 @@
+package update
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hitsan/sigscope/internal/model"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// action is a named operation that keys are bound to
+type action struct {
+	desc string // Shown in the help overlay
+	run  func(m *model.Model) tea.Cmd
+	jump bool // Recorded in the view history so ctrl+o can return
+}
+
+// actions is the registry of bindable actions, by name
+var actions = map[string]action{
+	"quit": {desc: "Quit", run: func(m *model.Model) tea.Cmd { return tea.Quit }},
+
+	// Signal navigation and row ordering
+	"signal_down":    {desc: "Select the next row", run: do((*model.Model).MoveSignalDown)},
+	"signal_up":      {desc: "Select the previous row", run: do((*model.Model).MoveSignalUp)},
+	"row_down":       {desc: "Move the selected row down", run: do((*model.Model).MoveRowDown)},
+	"row_up":         {desc: "Move the selected row up", run: do((*model.Model).MoveRowUp)},
+	"insert_divider": {desc: "Insert a divider below the selected row", run: prompt(model.PromptDivider, "Divider label")},
+	"create_group":   {desc: "Wrap the selected row in a new group", run: prompt(model.PromptGroup, "Group name")},
+	"remove_entry":   {desc: "Delete a divider / ungroup a group", run: do((*model.Model).RemoveSelectedEntry)},
+
+	// Time navigation
+	"scroll_left":  {desc: "Scroll time left", run: scrollTime(-10)},
+	"scroll_right": {desc: "Scroll time right", run: scrollTime(10)},
+	"page_left":    {desc: "Scroll time left by half a window", run: scrollTime(-2)},
+	"page_right":   {desc: "Scroll time right by half a window", run: scrollTime(2)},
+	"goto_start":   {desc: "Jump to the start", run: do((*model.Model).GoToStart), jump: true},
+	"goto_end":     {desc: "Jump to the end", run: do((*model.Model).GoToEnd), jump: true},
+	"prev_change":  {desc: "Jump to the previous transition", run: do((*model.Model).PrevChange), jump: true},
+	"next_change":  {desc: "Jump to the next transition", run: do((*model.Model).NextChange), jump: true},
+	"next_marker":  {desc: "Jump to the next marker", run: do((*model.Model).NextMarker), jump: true},
+
+	// Zoom
+	"zoom_in":       {desc: "Zoom in", run: do((*model.Model).ZoomIn), jump: true},
+	"zoom_out":      {desc: "Zoom out", run: do((*model.Model).ZoomOut), jump: true},
+	"zoom_reset":    {desc: "Show the whole dump", run: do((*model.Model).ResetZoom), jump: true},
+	"zoom_prompt":   {desc: "Zoom to a range, marker span or level", run: prompt(model.PromptZoom, "Zoom (t1 t2 | A B | 4x | 100ns)")},
+	"zoom_activity": {desc: "Zoom to the selected signal's activity", run: report((*model.Model).ZoomToSignalActivity), jump: true},
+
+	// View history
+	"history_back": {desc: "Go back to the previous view", run: func(m *model.Model) tea.Cmd {
+		if !m.HistoryBack() {
+			m.StatusMessage = "No older view"
+		}
+		return nil
+	}},
+	"history_forward": {desc: "Go forward to the next view", run: func(m *model.Model) tea.Cmd {
+		if !m.HistoryForward() {
+			m.StatusMessage = "No newer view"
+		}
+		return nil
+	}},
+
+	// Display
+	"toggle_cursor": {desc: "Toggle the cursor", run: func(m *model.Model) tea.Cmd {
+		m.CursorVisible = !m.CursorVisible
+		return nil
+	}},
+	"toggle_tall":     {desc: "Toggle tall (multi-row) waveforms", run: do((*model.Model).ToggleTallMode)},
+	"toggle_overview": {desc: "Toggle the overview bar", run: do((*model.Model).ToggleOverview)},
+	"toggle_follow":   {desc: "Pause / resume following a growing dump", run: do((*model.Model).ToggleFollow)},
+	"toggle_log":      {desc: "Toggle the run log pane", run: do((*model.Model).ToggleRunLog)},
+
+	// Search and command line
+	"search": {desc: "Search signal names", run: func(m *model.Model) tea.Cmd {
+		m.Mode = model.ModeSearch
+		m.SearchQuery = ""
+		return nil
+	}},
+	"command": {desc: "Open the command line", run: func(m *model.Model) tea.Cmd {
+		m.Mode = model.ModeCommand
+		m.Command.Start()
+		return nil
+	}},
+
+	// Signal selection mode
+	"select_mode":       {desc: "Toggle signal selection mode", run: do((*model.Model).ToggleSelectMode)},
+	"toggle_visibility": {desc: "Show / hide the selected signal", run: inSelectMode((*model.Model).ToggleSignalVisibility)},
+	"show_all":          {desc: "Show all signals", run: inSelectMode(func(m *model.Model) { m.SetAllSignalsVisible(true) })},
+	"hide_all":          {desc: "Hide all signals", run: inSelectMode(func(m *model.Model) { m.SetAllSignalsVisible(false) })},
+
+	// Buses, groups and virtual buses
+	"expand": {desc: "Expand / collapse a bus or group", run: func(m *model.Model) tea.Cmd {
+		if !m.ToggleGroupCollapse() {
+			m.ToggleBusExpansion()
+		}
+		return nil
+	}},
+	"pick":        {desc: "Pick a 1-bit signal for a virtual bus", run: do((*model.Model).TogglePick)},
+	"virtual_bus": {desc: "Combine picked signals into a virtual bus", run: prompt(model.PromptVirtualBus, "Virtual bus name")},
+
+	// Markers and radix
+	"toggle_marker": {desc: "Toggle a marker at the cursor", run: do((*model.Model).ToggleMarker)},
+	"cycle_radix":   {desc: "Cycle the radix of the selected bus", run: do((*model.Model).CycleSignalRadix)},
+
+	// Session
+	"save_session": {desc: "Save the session", run: func(m *model.Model) tea.Cmd {
+		saveSession(m, "")
+		return nil
+	}},
+
+	// Rebuild
+	"run": {desc: "Run the rebuild command, reload on success", run: startRun},
+
+	// Dumps of the watched directory
+	"dump_picker": {desc: "Pick a dump of the watched directory", run: report((*model.Model).OpenPicker)},
+	"newer_dump":  {desc: "Open the next newer dump of the directory", run: openNewerDump},
+}
+
+// defaultKeys are the built-in key bindings
+var defaultKeys = map[string]string{
+	"q":      "quit",
+	"ctrl+c": "quit",
+
+	"j":          "signal_down",
+	"down":       "signal_down",
+	"k":          "signal_up",
+	"up":         "signal_up",
+	"J":          "row_down",
+	"shift+down": "row_down",
+	"K":          "row_up",
+	"shift+up":   "row_up",
+	"d":          "insert_divider",
+	"n":          "create_group",
+	"x":          "remove_entry",
+
+	"h":           "scroll_left",
+	"left":        "scroll_left",
+	"l":           "scroll_right",
+	"right":       "scroll_right",
+	"H":           "page_left",
+	"shift+left":  "page_left",
+	"L":           "page_right",
+	"shift+right": "page_right",
+	"g":           "goto_start",
+	"G":           "goto_end",
+	"[":           "prev_change",
+	"]":           "next_change",
+	"'":           "next_marker",
+
+	"+": "zoom_in",
+	"=": "zoom_in",
+	"-": "zoom_out",
+	"_": "zoom_out",
+	"0": "zoom_reset",
+	"z": "zoom_prompt",
+	"Z": "zoom_activity",
+
+	"ctrl+o": "history_back",
+	"tab":    "history_forward",
+
+	"c": "toggle_cursor",
+	"t": "toggle_tall",
+	"o": "toggle_overview",
+	"f": "toggle_follow",
+	"O": "toggle_log",
+	"/": "search",
+	":": "command",
+
+	"s": "select_mode",
+	" ": "toggle_visibility",
+	"a": "show_all",
+	"A": "hide_all",
+
+	"e": "expand",
+	"v": "pick",
+	"V": "virtual_bus",
+	"m": "toggle_marker",
+	"r": "cycle_radix",
+	"S": "save_session",
+	"R": "run",
+	"D": "dump_picker",
+	"N": "newer_dump",
+
+	"?":  "help",
+	"f1": "help",
+}
+
+// keyMap returns the key map in effect
+func keyMap(m model.Model) map[string]string {
+	if m.KeyMap == nil {
+		return defaultKeys
+	}
+	return m.KeyMap
+}
+
+// KeyMap returns the default bindings with the given bindings applied on
+// top. A binding is an action name, a command line starting with ":"
+// (e.g. ":zoom 4x"), or "" to unbind the key.
+func KeyMap(bindings map[string]string) (map[string]string, error) {
+	keys := make(map[string]string, len(defaultKeys)+len(bindings))
+	for key, name := range defaultKeys {
+		keys[key] = name
+	}
+	for key, name := range bindings {
+		if key == "space" {
+			key = " "
+		}
+		if name == "" {
+			delete(keys, key)
+			continue
+		}
+		if _, ok := actions[name]; !ok && !strings.HasPrefix(name, ":") {
+			return nil, fmt.Errorf("unknown action %q for key %q (actions: %s)", name, key, strings.Join(ActionNames(), ", "))
+		}
+		keys[key] = name
+	}
+	return keys, nil
+}
+
+// ActionNames returns the names of all bindable actions, sorted
+func ActionNames() []string {
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runBinding runs the action or command line bound to a key
+func runBinding(m *model.Model, name string) tea.Cmd {
+	before := m.NavigationState()
+	if line, ok := strings.CutPrefix(name, ":"); ok {
+		cmd, err := runCommand(m, line)
+		if err != nil {
+			m.StatusMessage = err.Error()
+		}
+		m.RecordJump(before)
+		return cmd
+	}
+
+	a, ok := actions[name]
+	if !ok {
+		return nil
+	}
+	cmd := a.run(m)
+	if a.jump {
+		m.RecordJump(before)
+	}
+	return cmd
+}
+
+// do adapts a model method to an action
+func do(f func(m *model.Model)) func(m *model.Model) tea.Cmd {
+	return func(m *model.Model) tea.Cmd {
+		f(m)
+		return nil
+	}
+}
+
+// report adapts a model method that can fail, showing the error in the status bar
+func report(f func(m *model.Model) error) func(m *model.Model) tea.Cmd {
+	return func(m *model.Model) tea.Cmd {
+		if err := f(m); err != nil {
+			m.StatusMessage = err.Error()
+		}
+		return nil
+	}
+}
+
+// inSelectMode adapts a model method that only applies in signal selection mode
+func inSelectMode(f func(m *model.Model)) func(m *model.Model) tea.Cmd {
+	return func(m *model.Model) tea.Cmd {
+		if m.SelectMode {
+			f(m)
+		}
+		return nil
+	}
+}
+
+// prompt returns an action that opens a text prompt
+func prompt(kind model.PromptKind, label string) func(m *model.Model) tea.Cmd {
+	return func(m *model.Model) tea.Cmd {
+		m.Mode = model.ModePrompt
+		m.Prompt = model.Prompt{Kind: kind, Label: label}
+		return nil
+	}
+}
+
+// scrollTime returns an action that scrolls by 1/divisor of the window,
+// to the left for a negative divisor
+func scrollTime(divisor int) func(m *model.Model) tea.Cmd {
+	return func(m *model.Model) tea.Cmd {
+		amount := (m.TimeEnd - m.TimeStart) / uint64(max(divisor, -divisor))
+		if amount < 1 {
+			amount = 1
+		}
+		if divisor < 0 {
+			m.ScrollTimeLeft(amount)
+		} else {
+			m.ScrollTimeRight(amount)
+		}
+		return nil
+	}
+}
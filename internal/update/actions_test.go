@@ -0,0 +1,47 @@
+package update
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyMap(t *testing.T) {
+	keys, err := KeyMap(map[string]string{
+		"ctrl+o": "quit",
+		"Z":      ":zoom 4x",
+		"space":  "signal_down",
+		"j":      "",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key  string
+		want string
+		ok   bool
+	}{
+		{"ctrl+o", "quit", true},
+		{"Z", ":zoom 4x", true},
+		{" ", "signal_down", true},
+		{"j", "", false},
+		{"q", "quit", true}, // Defaults stay
+	}
+	for _, tt := range tests {
+		if got, ok := keys[tt.key]; got != tt.want || ok != tt.ok {
+			t.Errorf("key %q bound to %q (%v), want %q (%v)", tt.key, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	_, err = KeyMap(map[string]string{"x": "explode"})
+	if err == nil || !strings.HasPrefix(err.Error(), `unknown action "explode" for key "x"`) {
+		t.Errorf("error %v for an unknown action", err)
+	}
+}
+
+func TestDefaultKeysHaveActions(t *testing.T) {
+	for key, name := range defaultKeys {
+		if _, ok := actions[name]; !ok {
+			t.Errorf("key %q bound to unknown action %q", key, name)
+		}
+	}
+}
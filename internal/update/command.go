@@ -0,0 +1,259 @@
+package update
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hitsan/sigscope/internal/model"
+	"github.com/hitsan/sigscope/internal/render"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// command is a ":" command of the command line
+type command struct {
+	name  string
+	usage string // Arguments, shown in the help overlay
+	desc  string
+	// complete returns the candidates for argument n (0-based), or nil
+	complete func(m model.Model, n int, partial string) []string
+	run      func(m *model.Model, args []string) (tea.Cmd, error)
+}
+
+// commands lists the ":" commands; a unique prefix of a name also selects it
+var commands = []command{
+	{name: "goto", usage: "<time|marker>", desc: "Move the cursor", run: runGoto},
+	{name: "zoom", usage: "<t1 t2|A B|4x|100ns>", desc: "Zoom to a range, level or width", run: runZoom},
+	{name: "add", usage: "<pattern>...", desc: "Show signals matching a glob", complete: completeSignals, run: runShow(true)},
+	{name: "hide", usage: "<pattern>...", desc: "Hide signals matching a glob", complete: completeSignals, run: runShow(false)},
+	{name: "radix", usage: "<hex|bin|dec>", desc: "Set the radix of the selected bus", complete: completeRadix, run: runRadix},
+	{name: "marker", usage: "<A-Z> [time]", desc: "Place a marker", run: runMarker},
+	{name: "color", usage: "<color|none>", desc: "Color the selected signal", run: runColor},
+	{name: "export", usage: "csv <file>", desc: "Export the listed signals as CSV", complete: completeExport, run: runExport},
+	{name: "write", usage: "[file]", desc: "Save the session", run: runWrite},
+	{name: "quit", desc: "Quit", run: runQuit},
+}
+
+func handleCommandKey(m model.Model, msg tea.KeyMsg) (model.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.Mode = model.ModeNormal
+		line := m.Command.Input
+		m.Command.Remember(line)
+		before := m.NavigationState()
+		cmd, err := runCommand(&m, line)
+		if err != nil {
+			m.StatusMessage = err.Error()
+		}
+		m.RecordJump(before)
+		return m, cmd
+	case "esc":
+		m.Mode = model.ModeNormal
+	case "backspace":
+		// Deleting past the ":" leaves command mode, as in vim
+		if !m.Command.Backspace() {
+			m.Mode = model.ModeNormal
+		}
+	case "tab":
+		m.Command.Complete(func(words []string, partial string) []string {
+			return completeCommand(m, words, partial)
+		})
+	case "up":
+		m.Command.HistoryPrev()
+	case "down":
+		m.Command.HistoryNext()
+	default:
+		if len(msg.String()) == 1 {
+			m.Command.Type(msg.String())
+		}
+	}
+	return m, nil
+}
+
+// runCommand parses and executes one command line
+func runCommand(m *model.Model, line string) (tea.Cmd, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	cmd, err := lookupCommand(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	return cmd.run(m, fields[1:])
+}
+
+// lookupCommand finds a command by name or unique prefix ("q", "w", "exp")
+func lookupCommand(name string) (command, error) {
+	var found []command
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, nil
+		}
+		if strings.HasPrefix(cmd.name, name) {
+			found = append(found, cmd)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return command{}, fmt.Errorf("unknown command: %s", name)
+	case 1:
+		return found[0], nil
+	}
+	return command{}, fmt.Errorf("ambiguous command: %s", name)
+}
+
+// completeCommand returns the tab completion candidates for the next word
+func completeCommand(m model.Model, words []string, partial string) []string {
+	if len(words) == 0 {
+		names := make([]string, len(commands))
+		for i, cmd := range commands {
+			names[i] = cmd.name
+		}
+		return names
+	}
+	cmd, err := lookupCommand(words[0])
+	if err != nil || cmd.complete == nil {
+		return nil
+	}
+	return cmd.complete(m, len(words)-1, partial)
+}
+
+func completeSignals(m model.Model, n int, partial string) []string {
+	return m.SignalNameCandidates(partial)
+}
+
+func completeRadix(m model.Model, n int, partial string) []string {
+	if n > 0 {
+		return nil
+	}
+	names := make([]string, len(render.Radixes))
+	for i, r := range render.Radixes {
+		names[i] = string(r)
+	}
+	return names
+}
+
+func completeExport(m model.Model, n int, partial string) []string {
+	if n > 0 {
+		return nil
+	}
+	return []string{"csv"}
+}
+
+// usageError reports the usage of a command given the wrong arguments
+func usageError(usage string) error {
+	return fmt.Errorf("usage: :%s", usage)
+}
+
+func runGoto(m *model.Model, args []string) (tea.Cmd, error) {
+	if len(args) != 1 {
+		return nil, usageError("goto <time|marker>")
+	}
+	return nil, m.GoTo(args[0])
+}
+
+func runZoom(m *model.Model, args []string) (tea.Cmd, error) {
+	return nil, m.ZoomTo(strings.Join(args, " "))
+}
+
+// runShow returns the add (visible) or hide command
+func runShow(visible bool) func(m *model.Model, args []string) (tea.Cmd, error) {
+	return func(m *model.Model, args []string) (tea.Cmd, error) {
+		if len(args) == 0 {
+			if visible {
+				return nil, usageError("add <pattern>...")
+			}
+			return nil, usageError("hide <pattern>...")
+		}
+		total := 0
+		for _, pattern := range args {
+			count, err := m.SetMatchingVisible(pattern, visible)
+			if err != nil {
+				return nil, err
+			}
+			total += count
+		}
+		verb := "Shown"
+		if !visible {
+			verb = "Hidden"
+		}
+		m.StatusMessage = fmt.Sprintf("%s %d signal(s)", verb, total)
+		return nil, nil
+	}
+}
+
+func runRadix(m *model.Model, args []string) (tea.Cmd, error) {
+	if len(args) != 1 {
+		return nil, usageError("radix <hex|bin|dec>")
+	}
+	radix, ok := render.ParseRadix(args[0])
+	if !ok {
+		return nil, fmt.Errorf("unknown radix: %s", args[0])
+	}
+	sig := m.SelectedSignalData()
+	if sig == nil || sig.Signal.Width == 1 {
+		return nil, fmt.Errorf("select a bus to set its radix")
+	}
+	m.SetSignalRadix(radix)
+	return nil, nil
+}
+
+func runMarker(m *model.Model, args []string) (tea.Cmd, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, usageError("marker <A-Z> [time]")
+	}
+	name := strings.ToUpper(args[0])
+	if len(name) != 1 || name[0] < 'A' || name[0] > 'Z' {
+		return nil, fmt.Errorf("marker names are single letters A-Z")
+	}
+	t := m.CursorTime
+	if len(args) == 2 {
+		var err error
+		if t, err = m.ResolveTime(args[1]); err != nil {
+			return nil, err
+		}
+	}
+	m.SetMarker(name, t)
+	m.StatusMessage = fmt.Sprintf("Marker %s at %d", name, t)
+	return nil, nil
+}
+
+func runColor(m *model.Model, args []string) (tea.Cmd, error) {
+	if len(args) > 1 {
+		return nil, usageError("color <name|0-255|#rrggbb|none>")
+	}
+	spec := ""
+	if len(args) == 1 {
+		spec = args[0]
+	}
+	return nil, m.SetSignalColor(spec)
+}
+
+func runExport(m *model.Model, args []string) (tea.Cmd, error) {
+	if len(args) != 2 || args[0] != "csv" {
+		return nil, usageError("export csv <file>")
+	}
+	rows, err := m.ExportCSV(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to export: %v", err)
+	}
+	m.StatusMessage = fmt.Sprintf("Exported %d rows to %s", rows, args[1])
+	return nil, nil
+}
+
+func runWrite(m *model.Model, args []string) (tea.Cmd, error) {
+	if len(args) > 1 {
+		return nil, usageError("write [file]")
+	}
+	path := ""
+	if len(args) == 1 {
+		path = args[0]
+	}
+	saveSession(m, path)
+	return nil, nil
+}
+
+func runQuit(m *model.Model, args []string) (tea.Cmd, error) {
+	return tea.Quit, nil
+}
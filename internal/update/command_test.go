@@ -0,0 +1,96 @@
+package update
+
+import (
+	"testing"
+
+	"github.com/hitsan/sigscope/internal/model"
+)
+
+func TestLookupCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		err  string
+	}{
+		{"goto", "goto", ""},
+		{"g", "goto", ""},
+		{"exp", "export", ""},
+		{"q", "quit", ""},
+		{"gotox", "", "unknown command: gotox"},
+		{"", "", "ambiguous command: "},
+	}
+	for _, tt := range tests {
+		cmd, err := lookupCommand(tt.name)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("lookupCommand(%q): error %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || cmd.name != tt.want {
+			t.Errorf("lookupCommand(%q) = %q, %v, want %q", tt.name, cmd.name, err, tt.want)
+		}
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		line  string
+		err   string
+		check func(m model.Model) bool
+	}{
+		{"goto 40", "", func(m model.Model) bool { return m.CursorTime == 40 }},
+		{"  g   60  ", "", func(m model.Model) bool { return m.CursorTime == 60 }},
+		{"goto", "usage: :goto <time|marker>", nil},
+		{"goto 500", "500 is past the end of the dump", nil},
+		{"hide top.c*", "", func(m model.Model) bool { return !m.SignalVisible[0] && m.SignalVisible[1] }},
+		{"hide [", `invalid pattern "["`, nil},
+		{"radix oct", "unknown radix: oct", nil},
+		{"radix bin", "select a bus to set its radix", nil},
+		{"frobnicate", "unknown command: frobnicate", nil},
+		{"", "", func(m model.Model) bool { return true }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			m := testModel(t)
+			_, err := runCommand(&m, tt.line)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(m) {
+				t.Error("command had no effect")
+			}
+		})
+	}
+}
+
+func TestCompleteCommand(t *testing.T) {
+	m := testModel(t)
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"ra", "radix "},
+		{"radix h", "radix hex "},
+		{"add top.d", "add top.data "},
+		{"export ", "export csv "},
+		{"goto 1", "goto 1"},
+	}
+	for _, tt := range tests {
+		c := model.CommandLine{}
+		c.Start()
+		c.Type(tt.input)
+		c.Complete(func(words []string, partial string) []string {
+			return completeCommand(m, words, partial)
+		})
+		if c.Input != tt.want {
+			t.Errorf("completing %q gave %q, want %q", tt.input, c.Input, tt.want)
+		}
+	}
+}
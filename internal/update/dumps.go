@@ -0,0 +1,102 @@
+package update
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/hitsan/sigscope/internal/model"
+	"github.com/hitsan/sigscope/internal/watcher"
+	"github.com/hitsan/sigscope/pkg/waveform"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func handlePickerKey(m model.Model, msg tea.KeyMsg) (model.Model, tea.Cmd) {
+	switch msg.String() {
+	case "j", "down":
+		m.MovePicker(1)
+	case "k", "up":
+		m.MovePicker(-1)
+	case "g", "home":
+		m.MovePicker(-len(m.Dumps))
+	case "G", "end":
+		m.MovePicker(len(m.Dumps))
+	case "enter":
+		m.Mode = model.ModeNormal
+		if dump, ok := m.PickedDump(); ok && dump.Path != m.Filename {
+			return openDump(m, dump.Path)
+		}
+	case "esc", "q":
+		m.Mode = model.ModeNormal
+	}
+	return m, nil
+}
+
+// openNewerDump switches to the dump written after the open one
+func openNewerDump(m *model.Model) tea.Cmd {
+	dump, err := m.NewerDump()
+	if err != nil {
+		m.StatusMessage = err.Error()
+		return nil
+	}
+	*m, _ = openDump(*m, dump.Path)
+	return nil
+}
+
+// openDump switches to another dump of the watched directory, carrying
+// over the view state like a reload does
+func openDump(m model.Model, path string) (model.Model, tea.Cmd) {
+	var follower *waveform.Follower
+	var vcdFile *waveform.VCDFile
+	var err error
+	if m.Following() {
+		follower, err = waveform.Follow(path)
+		if err == nil {
+			vcdFile = follower.VCD()
+		}
+	} else {
+		vcdFile, err = m.Cache.Parse(path)
+	}
+	if err != nil {
+		m.StatusMessage = fmt.Sprintf("Failed to open %s: %v", filepath.Base(path), err)
+		return m, nil
+	}
+
+	m.Reload(vcdFile, path)
+	m.Follower = follower
+	if follower != nil {
+		m.FollowGrowth(0)
+	}
+	m.StatusMessage = "Opened " + filepath.Base(path)
+	return m, nil
+}
+
+// handleDirChanged lists the watched directory again after dumps changed,
+// reloads the open one if it was written and points out newer dumps
+func handleDirChanged(m model.Model, msg watcher.DirChangedMsg) (model.Model, tea.Cmd) {
+	current := false
+	dumps := false
+	for _, name := range msg.Names {
+		if model.IsDumpFile(name) {
+			dumps = true
+		}
+		if filepath.Join(m.DumpDir, name) == m.Filename {
+			current = true
+		}
+	}
+	if !dumps {
+		return m, nil
+	}
+
+	if err := m.RefreshDumps(); err != nil {
+		m.WatchError = err.Error()
+		return m, nil
+	}
+	if current {
+		m, _ = handleFileChanged(m, watcher.FileChangedMsg{Filename: m.Filename})
+	}
+	if dump, err := m.NewerDump(); err == nil {
+		m.StatusMessage = fmt.Sprintf("Newer dump: %s (%s to open)", filepath.Base(dump.Path), keyList(keyMap(m), "newer_dump"))
+	}
+	return m, nil
+}
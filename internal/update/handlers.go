@@ -1,20 +1,24 @@
 package update
 
 import (
-	"time"
+	"errors"
+	"fmt"
 
-	"sigscope/internal/model"
-	"sigscope/internal/vcd"
-	"sigscope/internal/watcher"
+	"github.com/hitsan/sigscope/internal/model"
+	"github.com/hitsan/sigscope/internal/session"
+	"github.com/hitsan/sigscope/internal/watcher"
+	"github.com/hitsan/sigscope/pkg/waveform"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// Update handles all key events and returns updated model
+// Update handles all key and mouse events and returns updated model
 func Update(m model.Model, msg tea.Msg) (model.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return handleKey(m, msg)
+	case tea.MouseMsg:
+		return handleMouse(m, msg)
 	case tea.WindowSizeMsg:
 		m.Width = msg.Width
 		m.Height = msg.Height
@@ -23,6 +27,12 @@ func Update(m model.Model, msg tea.Msg) (model.Model, tea.Cmd) {
 		return handleFileChanged(m, msg)
 	case watcher.FileWatchErrorMsg:
 		return handleWatchError(m, msg)
+	case watcher.DirChangedMsg:
+		return handleDirChanged(m, msg)
+	case runOutputMsg:
+		return handleRunOutput(m, msg)
+	case runDoneMsg:
+		return handleRunDone(m, msg)
 	}
 	return m, nil
 }
@@ -32,105 +42,33 @@ func handleKey(m model.Model, msg tea.KeyMsg) (model.Model, tea.Cmd) {
 	if m.Mode == model.ModeSearch {
 		return handleSearchKey(m, msg)
 	}
+	if m.Mode == model.ModePrompt {
+		return handlePromptKey(m, msg)
+	}
+	if m.Mode == model.ModeCommand {
+		return handleCommandKey(m, msg)
+	}
+	if m.Mode == model.ModeHelp {
+		return handleHelpKey(m, msg)
+	}
+	if m.Mode == model.ModePicker {
+		return handlePickerKey(m, msg)
+	}
 
-	switch msg.String() {
-	// Quit
-	case "q", "ctrl+c":
-		return m, tea.Quit
-
-	// Signal navigation (up/down)
-	case "j", "down":
-		m.MoveSignalDown()
-	case "k", "up":
-		m.MoveSignalUp()
-
-	// Time navigation (left/right)
-	case "h", "left":
-		scrollAmount := (m.TimeEnd - m.TimeStart) / 10
-		if scrollAmount < 1 {
-			scrollAmount = 1
-		}
-		m.ScrollTimeLeft(scrollAmount)
-	case "l", "right":
-		scrollAmount := (m.TimeEnd - m.TimeStart) / 10
-		if scrollAmount < 1 {
-			scrollAmount = 1
-		}
-		m.ScrollTimeRight(scrollAmount)
-
-	// Page navigation
-	case "H", "shift+left":
-		scrollAmount := (m.TimeEnd - m.TimeStart) / 2
-		if scrollAmount < 1 {
-			scrollAmount = 1
-		}
-		m.ScrollTimeLeft(scrollAmount)
-	case "L", "shift+right":
-		scrollAmount := (m.TimeEnd - m.TimeStart) / 2
-		if scrollAmount < 1 {
-			scrollAmount = 1
-		}
-		m.ScrollTimeRight(scrollAmount)
-
-	// Zoom
-	case "+", "=":
-		m.ZoomIn()
-	case "-", "_":
-		m.ZoomOut()
-	case "0":
-		m.ResetZoom()
-
-	// Jump to start/end
-	case "g":
-		m.GoToStart()
-	case "G":
-		m.GoToEnd()
-
-	// Cursor movement mode (toggle)
-	case "c":
-		m.CursorVisible = !m.CursorVisible
-
-	// Jump to prev/next value change
-	case "[":
-		m.PrevChange()
-	case "]":
-		m.NextChange()
-
-	// Search mode
-	case "/":
-		m.Mode = model.ModeSearch
-		m.SearchQuery = ""
-
-	// Signal selection mode
-	case "s":
-		m.ToggleSelectMode()
-
-	// Toggle signal visibility (select mode only)
-	case " ":
-		if m.SelectMode {
-			m.ToggleSignalVisibility()
-		}
+	m.StatusMessage = ""
 
-	// Show all signals (select mode only)
-	case "a":
-		if m.SelectMode {
-			m.SetAllSignalsVisible(true)
-		}
-
-	// Hide all signals (select mode only)
-	case "A":
-		if m.SelectMode {
-			m.SetAllSignalsVisible(false)
-		}
+	if name, ok := keyMap(m)[msg.String()]; ok {
+		return m, runBinding(&m, name)
 	}
-
 	return m, nil
 }
 
 func handleSearchKey(m model.Model, msg tea.KeyMsg) (model.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
+		before := m.NavigationState()
 		m.Search(m.SearchQuery)
+		m.RecordJump(before)
 		m.Mode = model.ModeNormal
 	case "esc":
 		m.Mode = model.ModeNormal
@@ -148,51 +86,109 @@ func handleSearchKey(m model.Model, msg tea.KeyMsg) (model.Model, tea.Cmd) {
 	return m, nil
 }
 
+func handlePromptKey(m model.Model, msg tea.KeyMsg) (model.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.Mode = model.ModeNormal
+		switch m.Prompt.Kind {
+		case model.PromptVirtualBus:
+			if err := m.CreateVirtualBus(m.Prompt.Input); err != nil {
+				m.StatusMessage = err.Error()
+			}
+		case model.PromptDivider:
+			m.InsertDivider(m.Prompt.Input)
+		case model.PromptGroup:
+			if err := m.CreateGroup(m.Prompt.Input); err != nil {
+				m.StatusMessage = err.Error()
+			}
+		case model.PromptZoom:
+			before := m.NavigationState()
+			if err := m.ZoomTo(m.Prompt.Input); err != nil {
+				m.StatusMessage = err.Error()
+			}
+			m.RecordJump(before)
+		}
+	case "esc":
+		m.Mode = model.ModeNormal
+	case "backspace":
+		if len(m.Prompt.Input) > 0 {
+			m.Prompt.Input = m.Prompt.Input[:len(m.Prompt.Input)-1]
+		}
+	default:
+		if len(msg.String()) == 1 {
+			m.Prompt.Input += msg.String()
+		}
+	}
+	return m, nil
+}
+
+// saveSession writes the current view to path, or to the session file if
+// path is empty
+func saveSession(m *model.Model, path string) {
+	if path == "" {
+		path = m.SessionFile
+	}
+	if path == "" {
+		path = session.DefaultPath(m.Filename)
+	}
+	if err := session.Save(path, session.FromModel(*m)); err != nil {
+		m.StatusMessage = fmt.Sprintf("Failed to save session: %v", err)
+		return
+	}
+	m.SessionFile = path
+	m.StatusMessage = fmt.Sprintf("Session saved to %s", path)
+}
+
 func handleFileChanged(m model.Model, msg watcher.FileChangedMsg) (model.Model, tea.Cmd) {
 	if msg.Error != nil {
 		m.WatchError = msg.Error.Error()
-		return m, watcher.WatchFile(m.Filename)
+		return m, nil
+	}
+	if m.Following() {
+		return handleFileGrew(m)
 	}
 
 	// VCDファイルを再パース
-	vcdFile, err := vcd.Parse(m.Filename)
+	vcdFile, err := m.Cache.Parse(m.Filename)
 	if err != nil {
 		m.ReloadError = err.Error()
-		return m, watcher.WatchFile(m.Filename)
-	}
-
-	// 現在の状態を保存
-	savedState := model.ViewState{
-		CursorTime:         m.CursorTime,
-		SelectedSignal:     m.SelectedSignal,
-		TimeStart:          m.TimeStart,
-		TimeEnd:            m.TimeEnd,
-		Zoom:               m.Zoom,
-		SignalScrollOffset: m.SignalScrollOffset,
-		SelectMode:         m.SelectMode,
-		SignalVisible:      append([]bool{}, m.SignalVisible...),
-		SignalNames:        m.ExtractSignalNames(),
+		return m, nil
 	}
 
-	// 新しいモデルを構築
-	newModel := model.NewModel(vcdFile, m.Filename)
-
-	// 状態を復元
-	newModel.RestoreViewState(savedState)
-
-	// 端末サイズを復元
-	newModel.Width = m.Width
-	newModel.Height = m.Height
+	m.Reload(vcdFile, m.Filename)
+	return m, nil
+}
 
-	// 再読み込み成功を記録
-	newModel.LastReloadTime = time.Now()
-	newModel.ReloadError = ""
-	newModel.WatchError = ""
+// handleFileGrew reads what was appended to a followed dump. A rewritten
+// file, e.g. from a restarted simulation, is followed again from the start.
+func handleFileGrew(m model.Model) (model.Model, tea.Cmd) {
+	oldEnd := m.VCD.EndTime
+	grew, err := m.Follower.Update()
+	if errors.Is(err, waveform.ErrRewritten) {
+		follower, err := waveform.Follow(m.Filename)
+		if err != nil {
+			m.ReloadError = err.Error()
+			return m, nil
+		}
+		m.Reload(follower.VCD(), m.Filename)
+		m.Follower = follower
+		m.FollowGrowth(0)
+		return m, nil
+	}
+	if err != nil {
+		m.ReloadError = err.Error()
+		return m, nil
+	}
 
-	return newModel, watcher.WatchFile(m.Filename)
+	m.ReloadError = ""
+	m.WatchError = ""
+	if grew {
+		m.FollowGrowth(oldEnd)
+	}
+	return m, nil
 }
 
 func handleWatchError(m model.Model, msg watcher.FileWatchErrorMsg) (model.Model, tea.Cmd) {
 	m.WatchError = msg.Error.Error()
-	return m, watcher.WatchFile(m.Filename)
+	return m, nil
 }
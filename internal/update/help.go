@@ -0,0 +1,145 @@
+package update
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hitsan/sigscope/internal/model"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// helpSection lists actions under a heading of the help overlay
+type helpSection struct {
+	title   string
+	actions []string
+}
+
+// helpSections orders the actions in the help overlay
+var helpSections = []helpSection{
+	{"Signals", []string{"signal_down", "signal_up", "search", "expand", "cycle_radix", "pick", "virtual_bus"}},
+	{"Rows and groups", []string{"row_down", "row_up", "insert_divider", "create_group", "remove_entry"}},
+	{"Time", []string{"scroll_left", "scroll_right", "page_left", "page_right", "goto_start", "goto_end",
+		"prev_change", "next_change", "toggle_marker", "next_marker", "history_back", "history_forward"}},
+	{"Zoom", []string{"zoom_in", "zoom_out", "zoom_reset", "zoom_prompt", "zoom_activity"}},
+	{"Display", []string{"toggle_cursor", "toggle_tall", "toggle_overview", "toggle_follow", "toggle_log"}},
+	{"Select mode", []string{"select_mode", "toggle_visibility", "show_all", "hide_all"}},
+	{"Dumps", []string{"dump_picker", "newer_dump"}},
+	{"General", []string{"command", "save_session", "run", "help", "quit"}},
+}
+
+// The help action lists the action registry, so it is registered here
+// rather than in its initializer
+func init() {
+	actions["help"] = action{desc: "Show this help", run: func(m *model.Model) tea.Cmd {
+		m.ShowHelp(helpLines(*m))
+		return nil
+	}}
+}
+
+func handleHelpKey(m model.Model, msg tea.KeyMsg) (model.Model, tea.Cmd) {
+	page := m.HelpHeight() - 1
+	switch msg.String() {
+	case "j", "down":
+		m.ScrollHelp(1)
+	case "k", "up":
+		m.ScrollHelp(-1)
+	case "pgdown", " ", "ctrl+d":
+		m.ScrollHelp(page)
+	case "pgup", "ctrl+u":
+		m.ScrollHelp(-page)
+	case "g", "home":
+		m.ScrollHelp(-len(m.Help))
+	case "G", "end":
+		m.ScrollHelp(len(m.Help))
+	case "esc", "q", "?", "f1", "enter":
+		m.CloseHelp()
+	}
+	return m, nil
+}
+
+// helpLines builds the help overlay from the key map in effect, followed by
+// the keys of the other modes, the commands and the mouse
+func helpLines(m model.Model) []model.HelpLine {
+	keys := keyMap(m)
+
+	var lines []model.HelpLine
+	section := func(title string) {
+		if len(lines) > 0 {
+			lines = append(lines, model.HelpLine{})
+		}
+		lines = append(lines, model.HelpLine{Text: title})
+	}
+
+	for _, s := range helpSections {
+		section(s.title)
+		for _, name := range s.actions {
+			lines = append(lines, model.HelpLine{Keys: keyList(keys, name), Text: actions[name].desc})
+		}
+	}
+
+	// Command lines bound from the config file
+	var custom []string
+	for key, name := range keys {
+		if strings.HasPrefix(name, ":") {
+			custom = append(custom, key)
+		}
+	}
+	if len(custom) > 0 {
+		sort.Strings(custom)
+		section("Custom bindings")
+		for _, key := range custom {
+			lines = append(lines, model.HelpLine{Keys: keyName(key), Text: keys[key]})
+		}
+	}
+
+	section("Command line (" + keyList(keys, "command") + ")")
+	lines = append(lines,
+		model.HelpLine{Keys: "tab", Text: "Complete commands and signal names"},
+		model.HelpLine{Keys: "↑ / ↓", Text: "Browse earlier commands"},
+		model.HelpLine{Keys: "enter / esc", Text: "Run / cancel"},
+	)
+	for _, cmd := range commands {
+		lines = append(lines, model.HelpLine{Keys: strings.TrimSpace(":" + cmd.name + " " + cmd.usage), Text: cmd.desc})
+	}
+
+	section("Search and prompts")
+	lines = append(lines,
+		model.HelpLine{Keys: "enter", Text: "Search / confirm"},
+		model.HelpLine{Keys: "esc", Text: "Cancel"},
+		model.HelpLine{Keys: "backspace", Text: "Delete a character"},
+	)
+
+	section("Mouse")
+	lines = append(lines,
+		model.HelpLine{Keys: "click overview", Text: "Move the window there (drag to scrub)"},
+		model.HelpLine{Keys: "click waveform", Text: "Move the cursor and select the row"},
+		model.HelpLine{Keys: "drag waveform", Text: "Zoom to the dragged range"},
+		model.HelpLine{Keys: "wheel waveform", Text: "Zoom around the pointer"},
+		model.HelpLine{Keys: "shift+wheel", Text: "Scroll time"},
+		model.HelpLine{Keys: "click name", Text: "Select the row; toggle a group or visibility"},
+		model.HelpLine{Keys: "wheel names", Text: "Move the selection"},
+	)
+	return lines
+}
+
+// keyList returns the keys bound to an action, e.g. "j / down", or "-"
+func keyList(keys map[string]string, action string) string {
+	bound := model.KeysFor(keys, action)
+	if len(bound) == 0 {
+		return "-"
+	}
+	names := make([]string, len(bound))
+	for i, key := range bound {
+		names[i] = keyName(key)
+	}
+	return strings.Join(names, " / ")
+}
+
+// keyName returns the display name of a key
+func keyName(key string) string {
+	if key == " " {
+		return "space"
+	}
+	return key
+}
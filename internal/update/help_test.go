@@ -0,0 +1,99 @@
+package update
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hitsan/sigscope/internal/model"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHelpListsEveryAction(t *testing.T) {
+	listed := map[string]int{}
+	for _, s := range helpSections {
+		for _, name := range s.actions {
+			if _, ok := actions[name]; !ok {
+				t.Errorf("help lists unknown action %q", name)
+			}
+			listed[name]++
+		}
+	}
+	for name := range actions {
+		if listed[name] != 1 {
+			t.Errorf("action %q listed %d times", name, listed[name])
+		}
+	}
+}
+
+// helpKeys returns the keys shown next to an action in the help overlay
+func helpKeys(lines []model.HelpLine, action string) string {
+	for _, line := range lines {
+		if line.Text == actions[action].desc {
+			return line.Keys
+		}
+	}
+	return ""
+}
+
+func TestHelpLines(t *testing.T) {
+	m := testModel(t)
+	if got := helpKeys(helpLines(m), "signal_down"); got != "j / down" {
+		t.Errorf("signal_down keys %q, want \"j / down\"", got)
+	}
+
+	keys, err := KeyMap(map[string]string{"j": "", "space": "signal_down", "Z": ":zoom 4x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.KeyMap = keys
+	lines := helpLines(m)
+	if got := helpKeys(lines, "signal_down"); got != "space / down" {
+		t.Errorf("rebound signal_down keys %q, want \"space / down\"", got)
+	}
+	found := false
+	for _, line := range lines {
+		if line == (model.HelpLine{Keys: "Z", Text: ":zoom 4x"}) {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("custom binding not listed")
+	}
+}
+
+func TestKeysFor(t *testing.T) {
+	keys := map[string]string{"ctrl+f": "page_right", "l": "page_right", "pgdown": "page_right", "f": "page_right", "j": "signal_down"}
+	want := []string{"f", "l", "ctrl+f", "pgdown"}
+	if got := model.KeysFor(keys, "page_right"); !reflect.DeepEqual(got, want) {
+		t.Errorf("keys %v, want %v", got, want)
+	}
+}
+
+func TestHelpKeys(t *testing.T) {
+	m := testModel(t)
+	m.Height = 10
+	m.ShowHelp(helpLines(m))
+
+	press := func(key string) {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		m, _ = handleHelpKey(m, msg)
+	}
+	press("j")
+	if m.HelpScroll != 1 {
+		t.Errorf("scroll %d after j, want 1", m.HelpScroll)
+	}
+	press("G")
+	if want := len(m.Help) - m.HelpHeight(); m.HelpScroll != want {
+		t.Errorf("scroll %d after G, want %d", m.HelpScroll, want)
+	}
+	press("g")
+	press("k")
+	if m.HelpScroll != 0 {
+		t.Errorf("scroll %d above the top", m.HelpScroll)
+	}
+	press("q")
+	if m.Mode != model.ModeNormal || m.Help != nil {
+		t.Errorf("help still open in mode %v", m.Mode)
+	}
+}
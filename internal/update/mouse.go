@@ -0,0 +1,132 @@
+package update
+
+import (
+	"github.com/hitsan/sigscope/internal/model"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// wheelZoomFactor is the zoom step of one wheel notch
+const wheelZoomFactor = 1.25
+
+// minDragColumns is the drag width below which a press/release counts as a click
+const minDragColumns = 2
+
+func handleMouse(m model.Model, msg tea.MouseMsg) (model.Model, tea.Cmd) {
+	// Prompts and search take the keyboard only
+	if m.Mode != model.ModeNormal {
+		return m, nil
+	}
+
+	// A drag keeps going even if the pointer leaves the waveform pane
+	if m.OverviewVisible && msg.Y == model.OverviewLine && msg.X >= m.WaveformLeft() && !m.Dragging {
+		handleOverviewMouse(&m, msg)
+	} else if msg.X >= m.WaveformLeft() || m.Dragging {
+		handleWaveformMouse(&m, msg)
+	} else {
+		handleSignalPaneMouse(&m, msg)
+	}
+	return m, nil
+}
+
+// handleWaveformMouse handles clicks, drags and the wheel in the waveform pane
+func handleWaveformMouse(m *model.Model, msg tea.MouseMsg) {
+	col := msg.X - m.WaveformLeft()
+	t := m.TimeAtColumn(col)
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp && !msg.Shift:
+		m.ZoomAt(t, wheelZoomFactor)
+	case msg.Button == tea.MouseButtonWheelDown && !msg.Shift:
+		m.ZoomAt(t, 1/wheelZoomFactor)
+	case msg.Button == tea.MouseButtonWheelLeft,
+		msg.Button == tea.MouseButtonWheelUp && msg.Shift:
+		m.ScrollTimeLeft(scrollStep(*m))
+	case msg.Button == tea.MouseButtonWheelRight,
+		msg.Button == tea.MouseButtonWheelDown && msg.Shift:
+		m.ScrollTimeRight(scrollStep(*m))
+
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		// Click sets the cursor and selects the row; a drag zooms
+		before := m.NavigationState()
+		m.CursorTime = t
+		if row := m.RowAtLine(msg.Y); row >= 0 {
+			m.SelectRow(row)
+		}
+		m.RecordJump(before)
+		m.Dragging = true
+		m.DragStartTime = t
+		m.DragEndTime = t
+	case msg.Action == tea.MouseActionMotion && m.Dragging:
+		m.DragEndTime = t
+	case msg.Action == tea.MouseActionRelease && m.Dragging:
+		m.Dragging = false
+		m.DragEndTime = t
+		if dragColumns(*m) >= minDragColumns {
+			before := m.NavigationState()
+			m.ZoomToRange(m.DragStartTime, m.DragEndTime)
+			m.RecordJump(before)
+		}
+	}
+}
+
+// handleOverviewMouse moves the window to the clicked point of the overview
+// bar; dragging along the bar scrubs through the dump
+func handleOverviewMouse(m *model.Model, msg tea.MouseMsg) {
+	if msg.Button != tea.MouseButtonLeft {
+		return
+	}
+	t := m.OverviewTimeAt(msg.X - m.WaveformLeft())
+	switch msg.Action {
+	case tea.MouseActionPress:
+		before := m.NavigationState()
+		m.CenterOn(t)
+		m.RecordJump(before)
+	case tea.MouseActionMotion:
+		m.CenterOn(t)
+	}
+}
+
+// handleSignalPaneMouse handles clicks and the wheel in the signal name pane
+func handleSignalPaneMouse(m *model.Model, msg tea.MouseMsg) {
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		m.MoveSignalUp()
+	case msg.Button == tea.MouseButtonWheelDown:
+		m.MoveSignalDown()
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		row := m.RowAtLine(msg.Y)
+		if row < 0 {
+			return
+		}
+		m.SelectRow(row)
+
+		// Select mode: clicking a signal toggles it; group headers open and close
+		if m.SelectMode {
+			m.ToggleSignalVisibility()
+		}
+		m.ToggleGroupCollapse()
+	}
+}
+
+// scrollStep returns the time scrolled by one wheel notch
+func scrollStep(m model.Model) uint64 {
+	step := (m.TimeEnd - m.TimeStart) / 10
+	if step < 1 {
+		step = 1
+	}
+	return step
+}
+
+// dragColumns returns the width of the current drag in waveform columns
+func dragColumns(m model.Model) int {
+	timeRange := m.TimeEnd - m.TimeStart
+	if timeRange == 0 {
+		return 0
+	}
+	start, end := m.DragStartTime, m.DragEndTime
+	if start > end {
+		start, end = end, start
+	}
+	return int(float64(end-start) / float64(timeRange) * float64(m.WaveformWidth()))
+}
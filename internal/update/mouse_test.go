@@ -0,0 +1,131 @@
+package update
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hitsan/sigscope/internal/model"
+	"github.com/hitsan/sigscope/pkg/waveform"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func testModel(t *testing.T) model.Model {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sim.vcd")
+	err := os.WriteFile(path, []byte(`$timescale 1ns $end
+$scope module top $end
+$var wire 1 ! clk $end
+$var wire 4 " data [3:0] $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+b0 "
+#100
+1!
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := waveform.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model.NewModel(v, "sim.vcd")
+}
+
+// mouseModel returns a model whose waveform pane spans 100 columns from
+// column 31, one nanosecond each
+func mouseModel(t *testing.T) model.Model {
+	t.Helper()
+	m := testModel(t)
+	m.Width, m.Height = 133, 20
+	m.SignalPaneWidth = 30
+	m.TimeStart, m.TimeEnd = 0, 100
+	if m.WaveformLeft() != 31 || m.WaveformWidth() != 100 {
+		t.Fatalf("waveform pane at %d, %d wide", m.WaveformLeft(), m.WaveformWidth())
+	}
+	return m
+}
+
+func press(x, y int) tea.MouseMsg {
+	return tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+}
+
+func motion(x, y int) tea.MouseMsg {
+	return tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionMotion}
+}
+
+func release(x, y int) tea.MouseMsg {
+	return tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonNone, Action: tea.MouseActionRelease}
+}
+
+func wheel(x int, button tea.MouseButton, shift bool) tea.MouseMsg {
+	return tea.MouseMsg{X: x, Y: 5, Button: button, Action: tea.MouseActionPress, Shift: shift}
+}
+
+func TestMouse(t *testing.T) {
+	row1 := mouseModel(t).HeaderHeight() + 1 // Second signal row
+	overview := model.OverviewLine
+	tests := []struct {
+		name       string
+		msgs       []tea.MouseMsg
+		cursor     uint64
+		start, end uint64
+		selected   int
+	}{
+		{"click sets the cursor and selects", []tea.MouseMsg{press(31+40, row1), release(31+40, row1)}, 40, 0, 100, 1},
+		{"click below the rows", []tea.MouseMsg{press(31+10, 15), release(31+10, 15)}, 10, 0, 100, 0},
+		{"drag zooms", []tea.MouseMsg{press(31+20, 3), motion(31+35, 3), release(31+60, 3)}, 20, 20, 60, 0},
+		{"drag right to left", []tea.MouseMsg{press(31+60, 3), release(31+20, 3)}, 60, 20, 60, 0},
+		{"short drag is a click", []tea.MouseMsg{press(31+20, 3), release(31+21, 3)}, 20, 0, 100, 0},
+		{"drag leaving the pane", []tea.MouseMsg{press(31+50, 3), release(5, 3)}, 50, 0, 50, 0},
+		{"wheel zooms at the pointer", []tea.MouseMsg{wheel(31+50, tea.MouseButtonWheelUp, false)}, 0, 10, 90, 0},
+		{"shift wheel scrolls", []tea.MouseMsg{
+			wheel(31+50, tea.MouseButtonWheelUp, false),
+			wheel(31+50, tea.MouseButtonWheelDown, true),
+		}, 0, 18, 98, 0},
+		{"wheel in the signal pane selects", []tea.MouseMsg{wheel(5, tea.MouseButtonWheelDown, false)}, 0, 0, 100, 1},
+		{"click in the signal pane", []tea.MouseMsg{press(5, row1)}, 0, 0, 100, 1},
+		{"click in the overview centers", []tea.MouseMsg{
+			wheel(31+50, tea.MouseButtonWheelUp, false),
+			wheel(31+50, tea.MouseButtonWheelUp, false),
+			press(31+40, overview), release(31+40, overview),
+		}, 0, 8, 72, 0},
+		{"drag in the overview pans", []tea.MouseMsg{
+			wheel(31+50, tea.MouseButtonWheelUp, false),
+			press(31+30, overview), motion(31+60, overview), release(31+60, overview),
+		}, 0, 20, 100, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mouseModel(t)
+			for _, msg := range tt.msgs {
+				m, _ = Update(m, msg)
+			}
+			if m.Dragging {
+				t.Error("still dragging")
+			}
+			if m.CursorTime != tt.cursor {
+				t.Errorf("cursor at %d, want %d", m.CursorTime, tt.cursor)
+			}
+			if m.TimeStart != tt.start || m.TimeEnd != tt.end {
+				t.Errorf("window [%d, %d], want [%d, %d]", m.TimeStart, m.TimeEnd, tt.start, tt.end)
+			}
+			if m.SelectedSignal != tt.selected {
+				t.Errorf("selected %d, want %d", m.SelectedSignal, tt.selected)
+			}
+		})
+	}
+}
+
+func TestMouseIgnoredInPrompts(t *testing.T) {
+	m := mouseModel(t)
+	m.Mode = model.ModeSearch
+	m, _ = Update(m, press(31+40, m.HeaderHeight()+1))
+	if m.CursorTime != 0 || m.SelectedSignal != 0 || m.Dragging {
+		t.Error("mouse handled while searching")
+	}
+}
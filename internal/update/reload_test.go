@@ -0,0 +1,109 @@
+package update
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hitsan/sigscope/internal/model"
+	"github.com/hitsan/sigscope/internal/render"
+	"github.com/hitsan/sigscope/internal/watcher"
+	"github.com/hitsan/sigscope/pkg/waveform"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const reloadHeader = `$timescale 1ns $end
+$scope module top $end
+$var wire 1 ! clk $end
+$var wire 4 " data [3:0] $end
+`
+
+// watchedModel returns a model of a dump file that the test can rewrite
+func watchedModel(t *testing.T) (model.Model, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sim.vcd")
+	if err := os.WriteFile(path, []byte(reloadHeader+"$upscope $end\n$enddefinitions $end\n#0\n0!\nb0 \"\n#100\n1!\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := waveform.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := model.NewModel(v, path)
+	m.Width, m.Height = 100, 20
+	return m, path
+}
+
+func TestReloadKeepsState(t *testing.T) {
+	m, path := watchedModel(t)
+	m.CursorTime = 60
+	m.ZoomToRange(20, 80)
+	m.SetMarker("A", 40)
+	m.ToggleTallMode()
+	m.Radix = map[string]render.Radix{"top.data": render.RadixBin}
+	m.SessionFile = "sim.session.json"
+
+	// Halfway through typing a command
+	m.Mode = model.ModeCommand
+	m.Command.Start()
+	m.Command.Type("goto 5")
+
+	dump := reloadHeader + "$var wire 1 # rst $end\n$upscope $end\n$enddefinitions $end\n#0\n0!\nb0 \"\n1#\n#200\n1!\n"
+	if err := os.WriteFile(path, []byte(dump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = Update(m, watcher.FileChangedMsg{Filename: path})
+
+	if m.ReloadError != "" || m.VCD.EndTime != 200 || m.LastReloadTime.IsZero() {
+		t.Fatalf("reload error %q, end %d", m.ReloadError, m.VCD.EndTime)
+	}
+	if got, want := m.ExtractSignalNames(), []string{"top.clk", "top.data", "top.rst"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("signals %v, want %v", got, want)
+	}
+	if m.Mode != model.ModeCommand || m.Command.Input != "goto 5" {
+		t.Errorf("mode %v, input %q after reloading", m.Mode, m.Command.Input)
+	}
+	if m.CursorTime != 60 || m.TimeStart != 20 || m.TimeEnd != 80 {
+		t.Errorf("cursor %d, window [%d, %d]", m.CursorTime, m.TimeStart, m.TimeEnd)
+	}
+	if len(m.Markers) != 1 || !m.TallMode || m.Radix["top.data"] != render.RadixBin || m.SessionFile != "sim.session.json" {
+		t.Errorf("markers %v, tall %v, radixes %v, session %q", m.Markers, m.TallMode, m.Radix, m.SessionFile)
+	}
+	if m.Width != 100 || m.Height != 20 {
+		t.Errorf("size %dx%d", m.Width, m.Height)
+	}
+}
+
+func keyMsg(key string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+func TestReloadKeepsSearch(t *testing.T) {
+	m, path := watchedModel(t)
+	m, _ = Update(m, keyMsg("/"))
+	m, _ = Update(m, keyMsg("d"))
+	m, _ = Update(m, watcher.FileChangedMsg{Filename: path})
+	if m.Mode != model.ModeSearch || m.SearchQuery != "d" {
+		t.Errorf("mode %v, query %q after reloading", m.Mode, m.SearchQuery)
+	}
+	for _, i := range m.SearchResult {
+		if i >= len(m.Signals) {
+			t.Errorf("search result %d past the signals", i)
+		}
+	}
+}
+
+func TestReloadError(t *testing.T) {
+	m, path := watchedModel(t)
+	m.CursorTime = 60
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	before := m.VCD
+	m, _ = Update(m, watcher.FileChangedMsg{Filename: path})
+	if m.ReloadError == "" || m.VCD != before || m.CursorTime != 60 {
+		t.Errorf("failed reload: error %q, dump replaced %v, cursor %d", m.ReloadError, m.VCD != before, m.CursorTime)
+	}
+}
@@ -0,0 +1,90 @@
+package update
+
+import (
+	"bufio"
+	"io"
+	"os/exec"
+	"time"
+
+	"github.com/hitsan/sigscope/internal/model"
+	"github.com/hitsan/sigscope/internal/watcher"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runOutputMsg carries an output line of the run command; more follow on ch
+type runOutputMsg struct {
+	line string
+	ch   <-chan tea.Msg
+}
+
+// runDoneMsg is sent when the run command exits
+type runDoneMsg struct {
+	err     error
+	elapsed time.Duration
+}
+
+// startRun launches the rebuild command through the shell. Its output and
+// exit are delivered one message at a time from a channel.
+func startRun(m *model.Model) tea.Cmd {
+	if err := m.StartRun(); err != nil {
+		m.StatusMessage = err.Error()
+		return nil
+	}
+
+	command := m.Run.Command
+	ch := make(chan tea.Msg)
+	go func() {
+		defer close(ch)
+		start := time.Now()
+
+		cmd := exec.Command("sh", "-c", command)
+		pr, pw := io.Pipe()
+		cmd.Stdout = pw
+		cmd.Stderr = pw
+		if err := cmd.Start(); err != nil {
+			ch <- runDoneMsg{err: err, elapsed: time.Since(start)}
+			return
+		}
+
+		// Close the pipe once the command is done so the scanner stops
+		done := make(chan error, 1)
+		go func() {
+			err := cmd.Wait()
+			pw.Close()
+			done <- err
+		}()
+
+		scanner := bufio.NewScanner(pr)
+		for scanner.Scan() {
+			ch <- runOutputMsg{line: scanner.Text(), ch: ch}
+		}
+		// Drain whatever is left, e.g. after an overlong line
+		io.Copy(io.Discard, pr)
+		ch <- runDoneMsg{err: <-done, elapsed: time.Since(start)}
+	}()
+	return waitRun(ch)
+}
+
+// waitRun waits for the next message of a run
+func waitRun(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+func handleRunOutput(m model.Model, msg runOutputMsg) (model.Model, tea.Cmd) {
+	m.AppendRunLog(msg.line)
+	return m, waitRun(msg.ch)
+}
+
+// handleRunDone reloads the dump after a successful run
+func handleRunDone(m model.Model, msg runDoneMsg) (model.Model, tea.Cmd) {
+	if !m.FinishRun(msg.err, msg.elapsed) {
+		return m, nil
+	}
+	status := m.StatusMessage
+	m, cmd := handleFileChanged(m, watcher.FileChangedMsg{Filename: m.Filename})
+	m.StatusMessage = status
+	return m, cmd
+}
@@ -0,0 +1,59 @@
+package view
+
+import (
+	"strings"
+
+	"github.com/hitsan/sigscope/internal/model"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpMaxWidth caps the width of the help box on wide terminals
+const helpMaxWidth = 78
+
+// helpKeyWidth is the width of the key column of the help overlay
+const helpKeyWidth = 30
+
+// renderHelp renders the scrolled help overlay in place of the main content
+func renderHelp(m model.Model) string {
+	inner := overlayWidth(m) - 4 // Border and padding
+	height := m.HelpHeight()
+
+	var lines []string
+	end := min(m.HelpScroll+height, len(m.Help))
+	for _, line := range m.Help[m.HelpScroll:end] {
+		switch {
+		case line.Keys == "" && line.Text == "":
+			lines = append(lines, "")
+		case line.Keys == "":
+			lines = append(lines, GroupStyle.Render(fitName(line.Text, inner)))
+		default:
+			keys := fitName(line.Keys, helpKeyWidth)
+			text := fitName(line.Text, max(inner-helpKeyWidth-1, 1))
+			lines = append(lines, SelectedSignalStyle.Render(keys)+" "+SignalNameStyle.Render(text))
+		}
+	}
+	return renderOverlay(m, lines)
+}
+
+// overlayWidth returns the outer width of an overlay box
+func overlayWidth(m model.Model) int {
+	return max(min(m.Width-2, helpMaxWidth), 6)
+}
+
+// renderOverlay draws lines in a box centered in place of the main content,
+// padded to the height of the help overlay
+func renderOverlay(m model.Model, lines []string) string {
+	width := overlayWidth(m)
+	for len(lines) < m.HelpHeight() {
+		lines = append(lines, "")
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(BorderStyle.GetForeground()).
+		Padding(0, 1).
+		Width(width - 2).
+		Render(strings.Join(lines, "\n"))
+	return lipgloss.PlaceHorizontal(m.Width, lipgloss.Center, box)
+}
@@ -0,0 +1,59 @@
+package view
+
+import (
+	"strings"
+
+	"github.com/hitsan/sigscope/internal/model"
+	"github.com/hitsan/sigscope/internal/render"
+)
+
+// renderOverview renders the overview bar: the selected signal's activity
+// over the whole dump, with the visible window highlighted and the markers
+// and cursor on top
+func renderOverview(m model.Model) string {
+	width := m.WaveformWidth()
+	end := m.VCD.EndTime
+
+	label := "overview"
+	if sig := m.SelectedSignalData(); sig != nil {
+		label = sig.Signal.Name
+	}
+	label = TimelineStyle.Render(fitName(" "+label, m.SignalPaneWidth))
+
+	cells := render.DensityCells(render.ActivityDensity(m.SelectedSignalData(), 0, end, width))
+
+	// Visible window
+	from, _ := render.RenderCursor(m.TimeStart, 0, end, width)
+	to, _ := render.RenderCursor(m.TimeEnd, 0, end, width)
+	for i := max(from, 0); i <= to && i < width; i++ {
+		cells[i].Kind = render.CellSelection
+	}
+
+	// Markers and cursor
+	for _, mk := range m.Markers {
+		if pos, ok := render.RenderCursor(mk.Time, 0, end, width); ok {
+			cells[pos] = render.Cell{Char: mk.Name, Kind: render.CellMarker}
+		}
+	}
+	if pos, ok := render.RenderCursor(m.CursorTime, 0, end, width); ok && m.CursorVisible {
+		cells[pos] = render.Cell{Char: "│", Kind: cells[pos].Kind}
+	}
+
+	var sb strings.Builder
+	for start := 0; start < len(cells); {
+		stop := start + 1
+		for stop < len(cells) && cells[stop].Kind == cells[start].Kind {
+			stop++
+		}
+		style := TimelineStyle
+		switch cells[start].Kind {
+		case render.CellSelection:
+			style = SelectionStyle
+		case render.CellMarker:
+			style = MarkerStyle
+		}
+		sb.WriteString(style.Render(render.CellsToString(cells[start:stop])))
+		start = stop
+	}
+	return label + SeparatorStyle.Render("│") + sb.String()
+}
@@ -0,0 +1,56 @@
+package view
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/hitsan/sigscope/internal/model"
+)
+
+// renderPicker renders the dumps of the watched directory in place of the
+// main content, newest first, scrolled to keep the selection in view
+func renderPicker(m model.Model) string {
+	inner := overlayWidth(m) - 4 // Border and padding
+	height := m.HelpHeight()
+
+	lines := []string{GroupStyle.Render(fitName("Dumps in "+m.DumpDir, inner))}
+	if len(m.Dumps) == 0 {
+		lines = append(lines, SignalNameStyle.Render("(none)"))
+	}
+
+	// One line is taken by the heading
+	first := max(0, m.PickerPos-(height-2))
+	last := min(first+height-1, len(m.Dumps))
+	for i := first; i < last; i++ {
+		dump := m.Dumps[i]
+		marker := NormalMarker
+		if m.IsCurrentDump(i) {
+			marker = SelectedMarker
+		}
+		info := fmt.Sprintf(" %9s  %s", formatSize(dump.Size), dump.ModTime.Format("2006-01-02 15:04:05"))
+		name := fitName(marker+filepath.Base(dump.Path), max(inner-len(info), 1))
+
+		style := SignalNameStyle
+		if i == m.PickerPos {
+			style = SelectedSignalStyle.Reverse(true)
+		}
+		lines = append(lines, style.Render(name+info))
+	}
+	return renderOverlay(m, lines)
+}
+
+// formatSize formats a file size in bytes for display
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n) / unit
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f TB", value)
+}
@@ -5,7 +5,7 @@ import (
 	"strings"
 	"time"
 
-	"sigscope/internal/model"
+	"github.com/hitsan/sigscope/internal/model"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -22,10 +22,22 @@ func Render(m model.Model) string {
 	title := renderTitle(m)
 	sections = append(sections, title)
 
-	// Main content (signals + waveforms)
-	content := renderMainContent(m)
+	// Main content (signals + waveforms), or the help overlay
+	var content string
+	if m.Mode == model.ModeHelp {
+		content = renderHelp(m)
+	} else if m.Mode == model.ModePicker {
+		content = renderPicker(m)
+	} else {
+		content = renderMainContent(m)
+	}
 	sections = append(sections, content)
 
+	// Output of the rebuild command
+	if m.Run.LogVisible {
+		sections = append(sections, renderRunLog(m))
+	}
+
 	// Status bar
 	status := renderStatusBar(m)
 	sections = append(sections, status)
@@ -83,6 +95,9 @@ func renderMainContent(m model.Model) string {
 
 	// Combine all parts
 	var result []string
+	if m.OverviewVisible {
+		result = append(result, renderOverview(m))
+	}
 	result = append(result, timelineRow)
 	result = append(result, SeparatorStyle.Render(separator))
 	result = append(result, contentLines...)
@@ -97,6 +112,17 @@ func renderStatusBar(m model.Model) string {
 	if m.Mode == model.ModeSearch {
 		// Search mode
 		status = fmt.Sprintf(" Search: %s█", m.SearchQuery)
+	} else if m.Mode == model.ModePrompt {
+		status = fmt.Sprintf(" %s: %s█", m.Prompt.Label, m.Prompt.Input)
+	} else if m.Mode == model.ModeCommand {
+		status = fmt.Sprintf(" :%s█", m.Command.Input)
+	} else if m.Mode == model.ModeHelp {
+		last := min(m.HelpScroll+m.HelpHeight(), len(m.Help))
+		status = fmt.Sprintf(" Help %d-%d/%d | j/k:scroll space:page esc:close", m.HelpScroll+1, last, len(m.Help))
+	} else if m.Mode == model.ModePicker {
+		status = fmt.Sprintf(" Dump %d/%d | j/k:move enter:open esc:close", min(m.PickerPos+1, len(m.Dumps)), len(m.Dumps))
+	} else if m.StatusMessage != "" {
+		status = " " + m.StatusMessage
 	} else {
 		// エラー表示（優先度: ReloadError > WatchError > 通常表示）
 		if m.ReloadError != "" {
@@ -107,7 +133,7 @@ func renderStatusBar(m model.Model) string {
 			timeStr := formatTimeStatus(m.CursorTime)
 			zoomStr := fmt.Sprintf("Zoom: %.1fx", m.Zoom)
 
-			helpStr := "j/k:↑↓ h/l:←→ +/-:zoom s:select /:search q:quit"
+			helpStr := "j/k:↑↓ h/l:←→ +/-:zoom /:search " + helpKey(m) + ":help q:quit"
 
 			// 再読み込み通知（3秒間表示）
 			reloadIndicator := ""
@@ -115,6 +141,20 @@ func renderStatusBar(m model.Model) string {
 				reloadIndicator = "[RELOADED] "
 			}
 
+			// 再実行中の表示
+			if m.Run.Running {
+				reloadIndicator = "[RUNNING] " + reloadIndicator
+			}
+
+			// 追従モードの表示
+			if m.Following() {
+				if m.FollowPaused {
+					reloadIndicator = "[PAUSED] " + reloadIndicator
+				} else {
+					reloadIndicator = "[FOLLOW] " + reloadIndicator
+				}
+			}
+
 			status = fmt.Sprintf(" %sTime: %s | %s | %s", reloadIndicator, timeStr, zoomStr, helpStr)
 		}
 	}
@@ -130,6 +170,14 @@ func renderStatusBar(m model.Model) string {
 	return StatusStyle.Render(status)
 }
 
+// helpKey returns the first key bound to the help overlay
+func helpKey(m model.Model) string {
+	if keys := model.KeysFor(m.KeyMap, "help"); len(keys) > 0 {
+		return keys[0]
+	}
+	return "?"
+}
+
 // formatTimeStatus formats time for status bar display
 func formatTimeStatus(t uint64) string {
 	if t >= 1000000000 {
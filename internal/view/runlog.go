@@ -0,0 +1,56 @@
+package view
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hitsan/sigscope/internal/model"
+)
+
+// ansiEscape matches terminal escape sequences in command output
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// renderRunLog renders the log pane: a title line with the command and its
+// state, then the tail of its output
+func renderRunLog(m model.Model) string {
+	state := m.Run.Result
+	if m.Run.Running {
+		state = "running"
+	}
+	// The state comes first so a long command can't push it out of view
+	title := "─ Run"
+	if state != "" {
+		title += " (" + state + ")"
+	}
+	if m.Run.Command == "" {
+		title += ": no command"
+	} else {
+		title += ": " + m.Run.Command
+	}
+	title = strings.TrimRight(fitName(title, m.Width), " ")
+	if pad := m.Width - len([]rune(title)); pad > 0 {
+		title += " " + strings.Repeat("─", pad-1)
+	}
+
+	lines := []string{SeparatorStyle.Render(title)}
+	height := model.RunLogLines - 1
+	from := max(len(m.Run.Log)-height, 0)
+	for _, line := range m.Run.Log[from:] {
+		lines = append(lines, fitName(cleanLogLine(line), m.Width))
+	}
+	for len(lines) < model.RunLogLines {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cleanLogLine makes an output line printable in one terminal row: escape
+// sequences are dropped, tabs expanded and only the last carriage-return
+// overwrite (e.g. of a progress bar) is kept
+func cleanLogLine(line string) string {
+	line = ansiEscape.ReplaceAllString(line, "")
+	if i := strings.LastIndex(line, "\r"); i >= 0 {
+		line = line[i+1:]
+	}
+	return strings.ReplaceAll(line, "\t", "    ")
+}
@@ -4,118 +4,170 @@ import (
 	"fmt"
 	"strings"
 
-	"sigscope/internal/model"
+	"github.com/hitsan/sigscope/internal/model"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
 // RenderSignalList renders the signal name list (left pane)
 func RenderSignalList(m model.Model) string {
 	if m.SelectMode {
-		return renderSelectModeListSingleLine(m)
+		return renderSelectModeList(m)
 	}
-	return renderNormalModeListSingleLine(m)
+	return renderNormalModeList(m)
 }
 
-// renderNormalModeListSingleLine renders signal list in normal mode (1-line per signal)
-func renderNormalModeListSingleLine(m model.Model) string {
+// renderNormalModeList renders signal list in normal mode
+func renderNormalModeList(m model.Model) string {
 	var lines []string
-	visibleCount := m.VisibleSignalCount()
-	indices := m.VisibleSignalIndices()
+	rows := m.DisplayRows()
 
-	// Determine which signals to show
+	// Determine which rows to show
 	startIdx := m.SignalScrollOffset
-	endIdx := startIdx + visibleCount
-	if endIdx > len(indices) {
-		endIdx = len(indices)
+	endIdx := startIdx + m.VisibleRowCount()
+	if endIdx > len(rows) {
+		endIdx = len(rows)
 	}
 
-	for vi := startIdx; vi < endIdx; vi++ {
-		globalIdx := indices[vi]
-		sig := m.Signals[globalIdx]
-		name := sig.Signal.Name
-
-		// Add bit width indicator for buses
-		if sig.Signal.Width > 1 {
-			name = fmt.Sprintf("%s[%d:0]", name, sig.Signal.Width-1)
-		}
+	for i := startIdx; i < endIdx; i++ {
+		row := rows[i]
 
 		// Truncate or pad name to fit
 		nameWidth := m.SignalPaneWidth - 2 // Reserve space for marker
-		if len(name) > nameWidth {
-			name = name[:nameWidth-1] + "…"
-		} else {
-			name = name + strings.Repeat(" ", nameWidth-len(name))
-		}
+		name := fitName(rowLabel(m, row), nameWidth)
 
 		// Apply style based on selection
 		var line string
-		if globalIdx == m.SelectedSignal {
+		if m.IsRowSelected(row) {
 			line = SelectedSignalStyle.Render(SelectedMarker + name)
 		} else {
-			line = SignalNameStyle.Render(NormalMarker + name)
+			line = rowStyle(m, row).Render(unselectedMarker(m, row) + name)
 		}
 
 		lines = append(lines, line)
+		lines = appendRowPadding(lines, m, row)
 	}
 
 	// Pad with empty lines if needed
-	for len(lines) < visibleCount {
+	for len(lines) < m.ContentHeight() {
 		lines = append(lines, strings.Repeat(" ", m.SignalPaneWidth))
 	}
 
 	return strings.Join(lines, "\n")
 }
 
-// renderSelectModeListSingleLine renders signal list in select mode (1-line per signal)
-func renderSelectModeListSingleLine(m model.Model) string {
+// renderSelectModeList renders signal list in select mode
+func renderSelectModeList(m model.Model) string {
 	var lines []string
-	visibleCount := m.VisibleSignalCount()
+	rows := m.DisplayRows()
 
-	// Determine which signals to show
+	// Determine which rows to show
 	startIdx := m.SignalScrollOffset
-	endIdx := startIdx + visibleCount
-	if endIdx > len(m.Signals) {
-		endIdx = len(m.Signals)
+	endIdx := startIdx + m.VisibleRowCount()
+	if endIdx > len(rows) {
+		endIdx = len(rows)
 	}
 
 	for i := startIdx; i < endIdx; i++ {
-		sig := m.Signals[i]
-		name := sig.Signal.Name
-
-		// Add bit width indicator for buses
-		if sig.Signal.Width > 1 {
-			name = fmt.Sprintf("%s[%d:0]", name, sig.Signal.Width-1)
-		}
-
-		// Checkbox marker
-		checkbox := UncheckedMarker
-		if m.SignalVisible[i] {
-			checkbox = CheckedMarker
+		row := rows[i]
+
+		// Checkbox marker (dividers and group headers have none)
+		checkbox := " "
+		if row.Kind == model.RowSignal {
+			checkbox = UncheckedMarker
+			if m.SignalVisible[row.Signal] {
+				checkbox = CheckedMarker
+			}
 		}
 
 		// Truncate or pad name to fit (reserve space for marker + checkbox + space)
 		nameWidth := m.SignalPaneWidth - 4
-		if len(name) > nameWidth {
-			name = name[:nameWidth-1] + "…"
-		} else {
-			name = name + strings.Repeat(" ", nameWidth-len(name))
-		}
+		name := fitName(rowLabel(m, row), nameWidth)
 
 		// Apply style based on selection
 		var line string
-		if i == m.SelectedSignal {
+		if m.IsRowSelected(row) {
 			line = SelectedSignalStyle.Render(SelectedMarker + checkbox + " " + name)
 		} else {
-			line = SignalNameStyle.Render(NormalMarker + checkbox + " " + name)
+			line = rowStyle(m, row).Render(unselectedMarker(m, row) + checkbox + " " + name)
 		}
 
 		lines = append(lines, line)
+		lines = appendRowPadding(lines, m, row)
 	}
 
 	// Pad with empty lines if needed
-	for len(lines) < visibleCount {
+	for len(lines) < m.ContentHeight() {
 		lines = append(lines, strings.Repeat(" ", m.SignalPaneWidth))
 	}
 
 	return strings.Join(lines, "\n")
 }
 
+// rowLabel returns the text shown for a row in the signal pane
+func rowLabel(m model.Model, row model.Row) string {
+	indent := strings.Repeat("  ", row.Depth)
+
+	switch row.Kind {
+	case model.RowDivider:
+		return indent + DividerMarker + " " + row.Entry.Name
+	case model.RowGroup:
+		if row.Entry.Collapsed {
+			return fmt.Sprintf("%s%s %s (%d)", indent, GroupCollapsedMarker, row.Entry.Name, len(row.Entry.Children))
+		}
+		return indent + GroupExpandedMarker + " " + row.Entry.Name
+	}
+
+	sig := m.Signals[row.Signal]
+	name := sig.Signal.Name
+
+	// Add bit width indicator for buses
+	if sig.Signal.Width > 1 {
+		name = fmt.Sprintf("%s[%d:0]", name, sig.Signal.Width-1)
+	}
+
+	// Indent bits of an expanded bus
+	if m.IsBusBit(row.Signal) {
+		name = "  " + name
+	}
+	return indent + name
+}
+
+// fitName truncates or pads a name to the given width
+func fitName(name string, width int) string {
+	length := len([]rune(name))
+	if length > width {
+		return string([]rune(name)[:width-1]) + "…"
+	}
+	return name + strings.Repeat(" ", width-length)
+}
+
+// appendRowPadding adds blank lines so a signal name spans its waveform rows
+func appendRowPadding(lines []string, m model.Model, row model.Row) []string {
+	for i := 1; i < m.RowHeight(row); i++ {
+		lines = append(lines, strings.Repeat(" ", m.SignalPaneWidth))
+	}
+	return lines
+}
+
+// unselectedMarker returns the row marker for a row that is not selected
+func unselectedMarker(m model.Model, row model.Row) string {
+	if row.Kind == model.RowSignal && m.IsPicked(row.Signal) {
+		return PickedMarker
+	}
+	return NormalMarker
+}
+
+// rowStyle returns the style of an unselected row
+func rowStyle(m model.Model, row model.Row) lipgloss.Style {
+	switch row.Kind {
+	case model.RowDivider:
+		return DividerStyle
+	case model.RowGroup:
+		return GroupStyle
+	}
+	if c := signalColor(m, row); c != "" {
+		return SignalNameStyle.Foreground(color(c))
+	}
+	return SignalNameStyle
+}
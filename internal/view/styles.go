@@ -3,61 +3,121 @@ package view
 import "github.com/charmbracelet/lipgloss"
 
 var (
-	// Title bar style
-	TitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("15")).
-			Background(lipgloss.Color("62")).
-			Padding(0, 1)
-
-	// Signal name styles
-	SignalNameStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252"))
-
-	SelectedSignalStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("46"))
-
 	// Marker for selected signal
 	SelectedMarker = "▶"
 	NormalMarker   = " "
+	PickedMarker   = "◆" // Picked for a virtual bus
+
+	// Divider and group header markers
+	DividerMarker        = "──"
+	GroupExpandedMarker  = "▾"
+	GroupCollapsedMarker = "▸"
 
 	// Checkbox markers for select mode
 	CheckedMarker   = "☑"
 	UncheckedMarker = "☐"
+)
+
+// Styles, set from the current theme by applyTheme
+var (
+	// Title bar style
+	TitleStyle lipgloss.Style
+
+	// Signal name styles
+	SignalNameStyle     lipgloss.Style
+	SelectedSignalStyle lipgloss.Style
+
+	// Divider and group header styles
+	DividerStyle lipgloss.Style
+	GroupStyle   lipgloss.Style
 
 	// Waveform styles
-	WaveformStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("40"))
+	WaveformStyle lipgloss.Style
+	BusValueStyle lipgloss.Style
 
-	BusValueStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("220"))
+	// Waveform cell styles for unknown, high-Z and glitch regions
+	UnknownStyle lipgloss.Style
+	HighZStyle   lipgloss.Style
+	GlitchStyle  lipgloss.Style
 
 	// Cursor style
-	CursorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
-			Bold(true)
+	CursorStyle lipgloss.Style
+
+	// Marker style
+	MarkerStyle lipgloss.Style
+
+	// Drag-to-zoom selection style
+	SelectionStyle lipgloss.Style
 
 	// Timeline style
-	TimelineStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("244"))
+	TimelineStyle lipgloss.Style
 
 	// Status bar style
-	StatusStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252")).
-			Background(lipgloss.Color("236")).
-			Padding(0, 1)
+	StatusStyle lipgloss.Style
 
 	// Search input style
-	SearchStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("220")).
-			Background(lipgloss.Color("236"))
+	SearchStyle lipgloss.Style
 
 	// Separator style
-	SeparatorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240"))
+	SeparatorStyle lipgloss.Style
 
 	// Border color
-	BorderStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240"))
+	BorderStyle lipgloss.Style
 )
+
+// applyTheme rebuilds the styles from a theme's colors
+func applyTheme(t Theme) {
+	fg := func(c string) lipgloss.Style {
+		return lipgloss.NewStyle().Foreground(color(c))
+	}
+
+	TitleStyle = fg(t.Title).
+		Bold(true).
+		Background(color(t.TitleBackground)).
+		Padding(0, 1)
+
+	SignalNameStyle = fg(t.SignalName)
+	SelectedSignalStyle = fg(t.Selected).Bold(true)
+
+	DividerStyle = fg(t.Divider)
+	GroupStyle = fg(t.Group).Bold(true)
+
+	WaveformStyle = fg(t.Waveform)
+	BusValueStyle = fg(t.BusValue)
+
+	UnknownStyle = fg(t.Unknown)
+	HighZStyle = fg(t.HighZ)
+	GlitchStyle = fg(t.Glitch).Bold(true)
+
+	CursorStyle = fg(t.Cursor).Bold(true)
+	MarkerStyle = fg(t.Marker)
+	SelectionStyle = lipgloss.NewStyle().Reverse(true)
+
+	TimelineStyle = fg(t.Timeline)
+
+	StatusStyle = fg(t.Status).
+		Background(color(t.StatusBackground)).
+		Padding(0, 1)
+
+	SearchStyle = fg(t.BusValue).Background(color(t.StatusBackground))
+
+	SeparatorStyle = fg(t.Separator)
+	BorderStyle = fg(t.Separator)
+
+	// Without colors, bars stand out by reversing the terminal colors
+	if t.TitleBackground == "" {
+		TitleStyle = TitleStyle.Reverse(true)
+		StatusStyle = StatusStyle.Reverse(true)
+	}
+	if t.Selected == "" {
+		SelectedSignalStyle = SelectedSignalStyle.Underline(true)
+	}
+}
+
+// color converts a theme color to a lipgloss color; "" means the terminal default
+func color(c string) lipgloss.TerminalColor {
+	if c == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(c)
+}
@@ -0,0 +1,107 @@
+package view
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Theme is a named palette of 256-color (or "#rrggbb") values.
+// An empty color leaves the terminal's default.
+type Theme struct {
+	Title, TitleBackground   string
+	SignalName, Selected     string
+	Divider, Group           string
+	Waveform, BusValue       string
+	Unknown, HighZ, Glitch   string
+	Cursor, Marker           string
+	Timeline, Separator      string
+	Status, StatusBackground string
+}
+
+// DefaultTheme is used when no theme is configured
+const DefaultTheme = "dark"
+
+// MonoTheme uses no colors; it is chosen when NO_COLOR is set
+const MonoTheme = "mono"
+
+// themes are the built-in themes by name
+var themes = map[string]Theme{
+	// For dark terminal backgrounds
+	"dark": {
+		Title: "15", TitleBackground: "62",
+		SignalName: "252", Selected: "46",
+		Divider: "244", Group: "75",
+		Waveform: "40", BusValue: "220",
+		Unknown: "196", HighZ: "226", Glitch: "201",
+		Cursor: "196", Marker: "51",
+		Timeline: "244", Separator: "240",
+		Status: "252", StatusBackground: "236",
+	},
+
+	// For light terminal backgrounds
+	"light": {
+		Title: "15", TitleBackground: "25",
+		SignalName: "236", Selected: "28",
+		Divider: "242", Group: "25",
+		Waveform: "24", BusValue: "130",
+		Unknown: "160", HighZ: "136", Glitch: "90",
+		Cursor: "160", Marker: "30",
+		Timeline: "242", Separator: "248",
+		Status: "236", StatusBackground: "254",
+	},
+
+	// Okabe-Ito palette: no red/green distinctions, for dark backgrounds
+	"colorblind": {
+		Title: "15", TitleBackground: "25",
+		SignalName: "252", Selected: "214",
+		Divider: "244", Group: "74",
+		Waveform: "74", BusValue: "227",
+		Unknown: "166", HighZ: "227", Glitch: "175",
+		Cursor: "214", Marker: "36",
+		Timeline: "244", Separator: "240",
+		Status: "252", StatusBackground: "236",
+	},
+
+	// No colors; emphasis only
+	MonoTheme: {},
+}
+
+// currentTheme is the name of the active theme
+var currentTheme = DefaultTheme
+
+func init() {
+	applyTheme(themes[DefaultTheme])
+}
+
+// SetTheme switches to a built-in theme
+func SetTheme(name string) error {
+	t, ok := themes[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown theme %q (themes: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	currentTheme = strings.ToLower(name)
+	applyTheme(t)
+	return nil
+}
+
+// ThemeNames returns the names of the built-in themes, sorted
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NoColor reports whether the NO_COLOR environment variable asks for no colors
+func NoColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// colorsEnabled reports whether per-signal colors are shown
+func colorsEnabled() bool {
+	return currentTheme != MonoTheme
+}
@@ -0,0 +1,87 @@
+package view
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestSetTheme(t *testing.T) {
+	defer SetTheme(DefaultTheme)
+
+	tests := []struct {
+		name    string
+		theme   string // Name it is known by, "" if unknown
+		colored bool
+	}{
+		{"dark", "dark", true},
+		{"Light", "light", true},
+		{"colorblind", "colorblind", true},
+		{"mono", "mono", false},
+		{"solarized", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetTheme(DefaultTheme)
+			err := SetTheme(tt.name)
+			if tt.theme == "" {
+				if err == nil || currentTheme != DefaultTheme {
+					t.Errorf("unknown theme: error %v, theme %s", err, currentTheme)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if currentTheme != tt.theme || colorsEnabled() != tt.colored {
+				t.Errorf("theme %s, colors %v", currentTheme, colorsEnabled())
+			}
+
+			_, noColor := WaveformStyle.GetForeground().(lipgloss.NoColor)
+			if noColor == tt.colored {
+				t.Errorf("waveform color %v", WaveformStyle.GetForeground())
+			}
+			// Without colors the bars are drawn reversed
+			if TitleStyle.GetReverse() == tt.colored || StatusStyle.GetReverse() == tt.colored {
+				t.Errorf("title reversed %v, status reversed %v", TitleStyle.GetReverse(), StatusStyle.GetReverse())
+			}
+		})
+	}
+}
+
+func TestThemeNames(t *testing.T) {
+	want := []string{"colorblind", "dark", "light", "mono"}
+	if got := ThemeNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("themes %v, want %v", got, want)
+	}
+	for _, name := range want {
+		if name == MonoTheme {
+			continue
+		}
+		// Every color of a colored theme is set
+		v := reflect.ValueOf(themes[name])
+		for i := range v.NumField() {
+			if v.Field(i).String() == "" {
+				t.Errorf("theme %s has no %s color", name, v.Type().Field(i).Name)
+			}
+		}
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", false},
+		{"1", true},
+		{"0", true}, // Any value counts, see no-color.org
+	}
+	for _, tt := range tests {
+		t.Setenv("NO_COLOR", tt.value)
+		if got := NoColor(); got != tt.want {
+			t.Errorf("NO_COLOR=%q: %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"fmt"
 	"strings"
 
-	"sigscope/internal/model"
+	"github.com/hitsan/sigscope/internal/model"
+	"github.com/hitsan/sigscope/internal/render"
 )
 
 // RenderTimeline renders the time axis header
@@ -67,6 +68,13 @@ func RenderTimeline(m model.Model) string {
 		}
 	}
 
+	// Label markers on the time axis
+	for _, mk := range m.Markers {
+		if pos, ok := render.RenderCursor(mk.Time, m.TimeStart, m.TimeEnd, width); ok {
+			result[pos] = mk.Name[0]
+		}
+	}
+
 	return TimelineStyle.Render(string(result))
 }
 
@@ -129,6 +137,17 @@ func GetGridPositions(m model.Model) []int {
 	return positions
 }
 
+// GetMarkerPositions returns the waveform columns of markers inside the window
+func GetMarkerPositions(m model.Model) []int {
+	var positions []int
+	for _, mk := range m.Markers {
+		if pos, ok := render.RenderCursor(mk.Time, m.TimeStart, m.TimeEnd, m.WaveformWidth()); ok {
+			positions = append(positions, pos)
+		}
+	}
+	return positions
+}
+
 // formatTime formats a time value with appropriate unit
 func formatTime(t uint64) string {
 	if t == 0 {
@@ -3,130 +3,181 @@ package view
 import (
 	"strings"
 
-	"sigscope/internal/model"
-	"sigscope/internal/render"
+	"github.com/hitsan/sigscope/internal/model"
+	"github.com/hitsan/sigscope/internal/render"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
-// RenderWaveforms renders all visible signal waveforms (right pane)
+// RenderWaveforms renders all listed signal waveforms (right pane).
+// Hidden signals in select mode, dividers and group headers get blank rows.
 func RenderWaveforms(m model.Model) string {
-	if m.SelectMode {
-		return renderSelectModeWaveformsSingleLine(m)
-	}
-	return renderNormalModeWaveformsSingleLine(m)
-}
-
-// renderNormalModeWaveformsSingleLine renders waveforms in normal mode (1-line per signal)
-func renderNormalModeWaveformsSingleLine(m model.Model) string {
 	var lines []string
-	visibleCount := m.VisibleSignalCount()
 	width := m.WaveformWidth()
-	indices := m.VisibleSignalIndices()
+	rows := m.DisplayRows()
 
-	// Determine which signals to show
+	// Determine which rows to show
 	startIdx := m.SignalScrollOffset
-	endIdx := startIdx + visibleCount
-	if endIdx > len(indices) {
-		endIdx = len(indices)
+	endIdx := startIdx + m.VisibleRowCount()
+	if endIdx > len(rows) {
+		endIdx = len(rows)
 	}
 
 	// Get cursor and grid positions
 	cursorPos, cursorVisible := render.RenderCursor(m.CursorTime, m.TimeStart, m.TimeEnd, width)
 	gridPositions := GetGridPositions(m)
+	markerPositions := GetMarkerPositions(m)
 
-	for vi := startIdx; vi < endIdx; vi++ {
-		globalIdx := indices[vi]
-		sig := m.Signals[globalIdx]
-
-		// Render waveform for this signal (single line)
-		waveform := render.RenderWaveformSingleLine(sig, m.TimeStart, m.TimeEnd, width)
-		runes := []rune(waveform)
+	for i := startIdx; i < endIdx; i++ {
+		row := rows[i]
 
-		// Apply grid lines
-		for _, pos := range gridPositions {
-			if pos < len(runes) && runes[pos] == ' ' {
-				runes[pos] = '┊'
+		var cells [][]render.Cell
+		if row.Kind == model.RowSignal && m.SignalVisible[row.Signal] {
+			cells = renderSignalRows(m, row.Signal, width)
+		} else {
+			cells = make([][]render.Cell, m.RowHeight(row))
+			for r := range cells {
+				cells[r] = blankCells(width)
 			}
 		}
 
-		// Apply cursor overlay if visible
-		if m.CursorVisible && cursorVisible && cursorPos >= 0 && cursorPos < len(runes) {
-			runes[cursorPos] = '│'
-		}
-
-		waveform = string(runes)
+		for _, line := range cells {
+			overlayGridAndCursor(m, line, gridPositions, markerPositions, cursorPos, cursorVisible)
 
-		// Apply different style for selected signal
-		if globalIdx == m.SelectedSignal {
-			lines = append(lines, SelectedSignalStyle.Render(waveform))
-		} else {
-			lines = append(lines, WaveformStyle.Render(waveform))
+			// Apply different style for selected signal
+			lines = append(lines, styleCells(line, m.IsRowSelected(row), signalColor(m, row)))
 		}
 	}
 
 	// Pad with empty lines if needed
-	for len(lines) < visibleCount {
+	for len(lines) < m.ContentHeight() {
 		lines = append(lines, strings.Repeat(" ", width))
 	}
 
 	return strings.Join(lines, "\n")
 }
 
+// renderSignalRows renders one signal as one row of cells, or several in tall mode
+func renderSignalRows(m model.Model, globalIdx int, width int) [][]render.Cell {
+	sig := m.Signals[globalIdx]
+	radix := m.SignalRadix(globalIdx)
 
-// renderSelectModeWaveformsSingleLine renders waveforms in select mode (1-line per signal)
-func renderSelectModeWaveformsSingleLine(m model.Model) string {
-	var lines []string
-	visibleCount := m.VisibleSignalCount()
-	width := m.WaveformWidth()
-
-	// Determine which signals to show
-	startIdx := m.SignalScrollOffset
-	endIdx := startIdx + visibleCount
-	if endIdx > len(m.Signals) {
-		endIdx = len(m.Signals)
+	var rows [][]render.Cell
+	if m.TallMode {
+		rows = render.RenderWaveformTallCells(sig, m.TimeStart, m.TimeEnd, width, radix)
+	} else {
+		rows = [][]render.Cell{render.RenderWaveformCells(sig, m.TimeStart, m.TimeEnd, width, radix)}
 	}
+	// An empty time window renders nothing; keep the row height
+	for r := range rows {
+		if rows[r] == nil {
+			rows[r] = blankCells(width)
+		}
+	}
+	return rows
+}
 
-	// Get cursor and grid positions
-	cursorPos, cursorVisible := render.RenderCursor(m.CursorTime, m.TimeStart, m.TimeEnd, width)
-	gridPositions := GetGridPositions(m)
-
-	for i := startIdx; i < endIdx; i++ {
-		var runes []rune
+// blankCells returns a row of empty cells
+func blankCells(width int) []render.Cell {
+	cells := make([]render.Cell, width)
+	for i := range cells {
+		cells[i] = render.Cell{Char: " "}
+	}
+	return cells
+}
 
-		if m.SignalVisible[i] {
-			sig := m.Signals[i]
-			waveform := render.RenderWaveformSingleLine(sig, m.TimeStart, m.TimeEnd, width)
-			runes = []rune(waveform)
-		} else {
-			runes = []rune(strings.Repeat(" ", width))
+// overlayGridAndCursor draws grid lines, markers and the cursor on top of a waveform row
+func overlayGridAndCursor(m model.Model, cells []render.Cell, gridPositions, markerPositions []int, cursorPos int, cursorVisible bool) {
+	// Apply grid lines
+	for _, pos := range gridPositions {
+		if pos < len(cells) && cells[pos].Char == " " {
+			cells[pos] = render.Cell{Char: "┊"}
 		}
+	}
 
-		// Apply grid lines
-		for _, pos := range gridPositions {
-			if pos < len(runes) && runes[pos] == ' ' {
-				runes[pos] = '┊'
-			}
+	// Apply marker lines
+	for _, pos := range markerPositions {
+		if pos >= 0 && pos < len(cells) {
+			cells[pos] = render.Cell{Char: "┆", Kind: render.CellMarker}
 		}
+	}
+
+	// Apply cursor overlay if visible
+	if m.CursorVisible && cursorVisible && cursorPos >= 0 && cursorPos < len(cells) {
+		cells[cursorPos] = render.Cell{Char: "│"}
+	}
 
-		// Apply cursor overlay if visible
-		if m.CursorVisible && cursorVisible && cursorPos >= 0 && cursorPos < len(runes) {
-			runes[cursorPos] = '│'
+	// Highlight the range being dragged
+	if m.Dragging {
+		from, to := dragColumns(m, len(cells))
+		for i := from; i <= to && i < len(cells); i++ {
+			cells[i].Kind = render.CellSelection
 		}
+	}
+}
 
-		waveform := string(runes)
+// dragColumns returns the first and last waveform columns of the drag range
+func dragColumns(m model.Model, width int) (int, int) {
+	from, _ := render.RenderCursor(m.DragStartTime, m.TimeStart, m.TimeEnd, width)
+	to, _ := render.RenderCursor(m.DragEndTime, m.TimeStart, m.TimeEnd, width)
+	if from > to {
+		from, to = to, from
+	}
+	if from < 0 {
+		from = 0
+	}
+	return from, to
+}
 
-		// Apply different style for selected signal
-		if i == m.SelectedSignal {
-			lines = append(lines, SelectedSignalStyle.Render(waveform))
-		} else {
-			lines = append(lines, WaveformStyle.Render(waveform))
+// styleCells renders a row of cells, styling runs of the same kind together.
+// A non-empty signalColor overrides the theme color of regular cells.
+func styleCells(cells []render.Cell, selected bool, signalColor string) string {
+	var sb strings.Builder
+	for start := 0; start < len(cells); {
+		end := start + 1
+		for end < len(cells) && cells[end].Kind == cells[start].Kind {
+			end++
+		}
+		style := cellStyle(cells[start].Kind, selected)
+		if cells[start].Kind == render.CellNormal && signalColor != "" {
+			style = style.Foreground(color(signalColor))
 		}
+		sb.WriteString(style.Render(render.CellsToString(cells[start:end])))
+		start = end
 	}
+	return sb.String()
+}
 
-	// Pad with empty lines if needed
-	for len(lines) < visibleCount {
-		lines = append(lines, strings.Repeat(" ", width))
+// cellStyle returns the style for a cell kind on a selected or unselected row
+func cellStyle(kind render.CellKind, selected bool) lipgloss.Style {
+	var style lipgloss.Style
+	switch kind {
+	case render.CellUnknown:
+		style = UnknownStyle
+	case render.CellHighZ:
+		style = HighZStyle
+	case render.CellGlitch:
+		style = GlitchStyle
+	case render.CellMarker:
+		style = MarkerStyle
+	case render.CellSelection:
+		style = SelectionStyle
+	default:
+		if selected {
+			return SelectedSignalStyle
+		}
+		return WaveformStyle
 	}
-
-	return strings.Join(lines, "\n")
+	if selected {
+		return style.Bold(true)
+	}
+	return style
 }
 
+// signalColor returns the color override of a signal row, or "" for the theme color
+func signalColor(m model.Model, row model.Row) string {
+	if row.Kind != model.RowSignal || !colorsEnabled() {
+		return ""
+	}
+	return m.SignalColor(row.Signal)
+}
@@ -1,10 +1,12 @@
 package watcher
 
 import (
+	"path/filepath"
+	"sort"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
 )
 
 // FileChangedMsg is sent when the watched file changes
@@ -18,53 +20,157 @@ type FileWatchErrorMsg struct {
 	Error error
 }
 
-// WatchFile creates a command that watches a file for changes
-func WatchFile(filename string) tea.Cmd {
-	return func() tea.Msg {
-		watcher, err := fsnotify.NewWatcher()
-		if err != nil {
-			return FileWatchErrorMsg{Error: err}
-		}
-		defer watcher.Close()
+// DirChangedMsg is sent when files of a watched directory were written,
+// created, removed or renamed
+type DirChangedMsg struct {
+	Dir   string
+	Names []string // Base names of the changed files, sorted
+}
 
-		err = watcher.Add(filename)
-		if err != nil {
-			return FileWatchErrorMsg{Error: err}
-		}
+// delay is how long a burst of writes is collected into one message
+const delay = 200 * time.Millisecond
 
-		// Debounce timer
-		const debounceDelay = 200 * time.Millisecond
-		var debounceTimer <-chan time.Time
+// Watcher reports changes of one file for the lifetime of the program.
+// It watches the parent directory rather than the file itself, so writers
+// that replace the file (write a temp file and rename it over the
+// original, or delete and recreate it) keep being noticed.
+type Watcher struct {
+	fs        *fsnotify.Watcher
+	filename  string // File or directory as given by the user, reported in messages
+	path      string // Absolute path of the file; "" watches the whole directory
+	target    string // What path links to, as fsnotify names writes to it
+	targetDir string // Directory watched for target besides that of path, or ""
+	follow    bool
+}
 
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					return FileWatchErrorMsg{Error: err}
-				}
+// New starts watching the directory of filename and, if it is a symlink,
+// that of the file it links to. With follow set, changes are reported at
+// most once per delay while writes go on, for a dump that is still being
+// written; otherwise once the writes have settled.
+func New(filename string, follow bool) (*Watcher, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fs.Add(filepath.Dir(abs)); err != nil {
+		fs.Close()
+		return nil, err
+	}
+	w := &Watcher{
+		fs:       fs,
+		filename: filename,
+		path:     abs,
+		follow:   follow,
+	}
+	w.resolve()
+	return w, nil
+}
 
-				// Filter for relevant events: WRITE, CREATE, RENAME
-				if event.Op&fsnotify.Write == fsnotify.Write ||
-					event.Op&fsnotify.Create == fsnotify.Create ||
-					event.Op&fsnotify.Rename == fsnotify.Rename {
+// resolve follows path to the file it links to now. Writes show up under
+// the name of the file written, not of a link to it, so the directory of
+// the target is watched, too. A link that can't be resolved yet, or that
+// is changed to point elsewhere, is resolved again on the next event.
+func (w *Watcher) resolve() {
+	w.target = w.path
+	if resolved, err := filepath.EvalSymlinks(w.path); err == nil {
+		w.target = resolved
+	}
 
-					// Reset debounce timer
-					debounceTimer = time.After(debounceDelay)
-				}
+	dir := filepath.Dir(w.target)
+	if dir == filepath.Dir(w.path) {
+		dir = ""
+	}
+	if dir == w.targetDir {
+		return
+	}
+	if w.targetDir != "" {
+		w.fs.Remove(w.targetDir)
+	}
+	w.targetDir = ""
+	if dir != "" && w.fs.Add(dir) == nil {
+		w.targetDir = dir
+	}
+}
+
+// NewDir starts watching every file of a directory. Changes are reported
+// as they are by New, but as a DirChangedMsg naming the changed files.
+func NewDir(dir string, follow bool) (*Watcher, error) {
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fs.Add(dir); err != nil {
+		fs.Close()
+		return nil, err
+	}
+	return &Watcher{fs: fs, filename: dir, follow: follow}, nil
+}
 
-			case <-debounceTimer:
-				// Debounce period elapsed, send file changed message
-				return FileChangedMsg{
-					Filename: filename,
-					Error:    nil,
+// Run streams messages to send until the watcher is closed; it is meant
+// to run in its own goroutine with a tea.Program's Send
+func (w *Watcher) Run(send func(tea.Msg)) {
+	var timer <-chan time.Time
+	changed := make(map[string]bool)
+
+	for {
+		select {
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if w.path != "" {
+				w.resolve()
+				if name := filepath.Clean(event.Name); name != w.path && name != w.target {
+					continue
 				}
 
-			case err, ok := <-watcher.Errors:
-				if !ok {
-					return FileWatchErrorMsg{Error: err}
+				// The file is gone for now (removed, or renamed away); a
+				// replacement shows up as a Create
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
 				}
-				return FileWatchErrorMsg{Error: err}
+			} else {
+				// In a directory, removed files matter too
+				if event.Op == fsnotify.Chmod {
+					continue
+				}
+				changed[filepath.Base(event.Name)] = true
 			}
+
+			// Collect a burst of writes; when following, later writes
+			// don't delay the message
+			if timer == nil || !w.follow {
+				timer = time.After(delay)
+			}
+
+		case <-timer:
+			timer = nil
+			if w.path != "" {
+				send(FileChangedMsg{Filename: w.filename})
+				continue
+			}
+			names := make([]string, 0, len(changed))
+			for name := range changed {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			changed = make(map[string]bool)
+			send(DirChangedMsg{Dir: w.filename, Names: names})
+
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			send(FileWatchErrorMsg{Error: err})
 		}
 	}
 }
+
+// Close stops watching; Run returns
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
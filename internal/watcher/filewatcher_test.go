@@ -0,0 +1,149 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// watch runs a watcher on filename and returns the messages it sends
+func watch(t *testing.T, filename string) <-chan tea.Msg {
+	t.Helper()
+	w, err := New(filename, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { w.Close() })
+	msgs := make(chan tea.Msg, 16)
+	go w.Run(func(msg tea.Msg) { msgs <- msg })
+	return msgs
+}
+
+// expectChange waits for a FileChangedMsg for filename
+func expectChange(t *testing.T, msgs <-chan tea.Msg, filename string) {
+	t.Helper()
+	select {
+	case msg := <-msgs:
+		changed, ok := msg.(FileChangedMsg)
+		if !ok || changed.Filename != filename {
+			t.Fatalf("got %#v, want a change of %s", msg, filename)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+}
+
+func TestWatcherReportsChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, dir, path string)
+	}{
+		{"written", func(t *testing.T, _, path string) {
+			if err := os.WriteFile(path, []byte("#10\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"replaced by rename", func(t *testing.T, dir, path string) {
+			tmp := filepath.Join(dir, "sim.vcd.tmp")
+			if err := os.WriteFile(tmp, []byte("#10\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Rename(tmp, path); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		for _, link := range []bool{false, true} {
+			name := tt.name
+			if link {
+				name += " through a symlink"
+			}
+			t.Run(name, func(t *testing.T) {
+				dir := t.TempDir()
+				path := filepath.Join(dir, "sim.vcd")
+				if err := os.WriteFile(path, []byte("#0\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				// The link is in another directory, as with a results link
+				watched := path
+				if link {
+					watched = filepath.Join(t.TempDir(), "latest.vcd")
+					if err := os.Symlink(path, watched); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				msgs := watch(t, watched)
+				tt.change(t, dir, path)
+				expectChange(t, msgs, watched)
+			})
+		}
+	}
+}
+
+// expectNoChange checks that nothing is reported for a while
+func expectNoChange(t *testing.T, msgs <-chan tea.Msg) {
+	t.Helper()
+	select {
+	case msg := <-msgs:
+		t.Fatalf("got %#v, want nothing", msg)
+	case <-time.After(4 * delay):
+	}
+}
+
+// relink points a symlink at target, replacing it at once as ln -sfn does
+func relink(t *testing.T, target, link string) {
+	t.Helper()
+	tmp := link + ".tmp"
+	if err := os.Symlink(target, tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcherFollowsRelinkedSymlink(t *testing.T) {
+	write := func(path, data string) {
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := filepath.Join(t.TempDir(), "run1.vcd")
+	write(old, "#0\n")
+	link := filepath.Join(t.TempDir(), "latest.vcd")
+	if err := os.Symlink(old, link); err != nil {
+		t.Fatal(err)
+	}
+	msgs := watch(t, link)
+
+	// A new run in another directory becomes the latest
+	next := filepath.Join(t.TempDir(), "run2.vcd")
+	write(next, "#0\n")
+	relink(t, next, link)
+	expectChange(t, msgs, link)
+
+	write(next, "#10\n")
+	expectChange(t, msgs, link)
+	write(old, "#10\n")
+	expectNoChange(t, msgs)
+}
+
+func TestWatcherDanglingSymlink(t *testing.T) {
+	dir := t.TempDir()
+	link := filepath.Join(dir, "latest.vcd")
+	if err := os.Symlink("sim.vcd", link); err != nil {
+		t.Fatal(err)
+	}
+	msgs := watch(t, link)
+
+	// The simulation writes the dump the link points to
+	if err := os.WriteFile(filepath.Join(dir, "sim.vcd"), []byte("#0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expectChange(t, msgs, link)
+}
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 
-	"sigscope/internal/cmd/query"
-	"sigscope/internal/model"
-	"sigscope/internal/update"
-	"sigscope/internal/vcd"
-	"sigscope/internal/view"
-	"sigscope/internal/watcher"
+	"github.com/hitsan/sigscope/internal/cmd/query"
+	"github.com/hitsan/sigscope/internal/config"
+	"github.com/hitsan/sigscope/internal/gtkw"
+	"github.com/hitsan/sigscope/internal/model"
+	"github.com/hitsan/sigscope/internal/session"
+	"github.com/hitsan/sigscope/internal/update"
+	"github.com/hitsan/sigscope/internal/view"
+	"github.com/hitsan/sigscope/internal/watcher"
+	"github.com/hitsan/sigscope/pkg/waveform"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -45,25 +50,204 @@ func main() {
 	}
 
 	// Default: launch TUI
-	filename := os.Args[1]
+	if err := runTUI(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runTUI parses TUI options and runs the interactive viewer
+func runTUI(args []string) error {
+	fs := flag.NewFlagSet("sigscope", flag.ContinueOnError)
+	fs.Usage = printUsage
+
+	var sessionFile string
+	fs.StringVar(&sessionFile, "S", "", "Session file to load and save")
+	fs.StringVar(&sessionFile, "session", "", "Session file to load and save")
+
+	var configFile string
+	fs.StringVar(&configFile, "config", "", "Config file (default: $XDG_CONFIG_HOME/sigscope/config.toml)")
+
+	var theme string
+	fs.StringVar(&theme, "theme", "", "Color theme: dark, light, colorblind or mono")
+
+	var run string
+	fs.StringVar(&run, "run", "", "Rebuild command started with the R key, e.g. \"make sim\"")
 
-	// Parse VCD file
-	vcdFile, err := vcd.Parse(filename)
+	var follow bool
+	fs.BoolVar(&follow, "f", false, "Follow a dump that is still being written (compressed dumps are re-read whole on each change)")
+	fs.BoolVar(&follow, "follow", false, "Follow a dump that is still being written (compressed dumps are re-read whole on each change)")
+
+	var dir string
+	fs.StringVar(&dir, "dir", "", "Watch a directory of dumps and open the newest")
+
+	var noCache bool
+	fs.BoolVar(&noCache, "no-cache", false, "Parse the dump without the parse cache")
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	// With --dir the newest dump is opened instead of a file argument
+	var dumps []model.DumpFile
+	var filename string
+	if dir != "" {
+		if fs.NArg() > 0 {
+			return fmt.Errorf("--dir takes no VCD file argument")
+		}
+		var err error
+		if dumps, err = model.ListDumps(dir); err != nil {
+			return fmt.Errorf("failed to list dumps: %w", err)
+		}
+		if len(dumps) == 0 {
+			return fmt.Errorf("no VCD files in %s", dir)
+		}
+		filename = dumps[0].Path
+	} else {
+		if fs.NArg() < 1 {
+			printUsage()
+			return fmt.Errorf("missing VCD file argument")
+		}
+		filename = fs.Arg(0)
+	}
+
+	// The keyboard is read from standard input, and reloading needs a file
+	if filename == "-" {
+		return fmt.Errorf("the viewer needs a VCD file; standard input (-) works with list and query")
+	}
+
+	var cache *waveform.Cache
+	if !noCache {
+		cache = waveform.DefaultCache()
+	}
+
+	// Parse VCD file; when following, only what has been written so far
+	var follower *waveform.Follower
+	var vcdFile *waveform.VCDFile
+	var err error
+	if follow {
+		follower, err = waveform.Follow(filename)
+		if err == nil {
+			vcdFile = follower.VCD()
+		}
+	} else {
+		vcdFile, err = cache.Parse(filename)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing VCD file: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to parse VCD file: %w", err)
 	}
 
 	// Create model
 	m := model.NewModel(vcdFile, filename)
+	m.Follower = follower
+	m.DumpDir = dir
+	m.Cache = cache
+	m.Dumps = dumps
+
+	// Apply preferences and key bindings
+	if err := loadConfig(&m, configFile, theme); err != nil {
+		return err
+	}
+	if run != "" {
+		m.Run.Command = run
+	}
+
+	// Restore session (a missing file is created on first save)
+	if sessionFile != "" {
+		if err := loadSession(&m, vcdFile, sessionFile); err != nil {
+			return err
+		}
+	}
+
+	// Watch for changes of the file or directory; the viewer works without, too
+	var w *watcher.Watcher
+	if dir != "" {
+		w, err = watcher.NewDir(dir, follow)
+	} else {
+		w, err = watcher.New(filename, follow)
+	}
+	if err != nil {
+		m.WatchError = err.Error()
+	}
 
 	// Create and run Bubble Tea program
-	p := tea.NewProgram(appModel{m}, tea.WithAltScreen())
+	p := tea.NewProgram(appModel{m}, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if w != nil {
+		defer w.Close()
+		go w.Run(p.Send)
+	}
 
 	if _, err := p.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("running program: %w", err)
+	}
+	return nil
+}
+
+// loadConfig applies the config file to the model. Without an explicit path
+// the default location is used, and a missing file means built-in defaults.
+// The theme flag overrides NO_COLOR, which overrides the configured theme.
+func loadConfig(m *model.Model, path, theme string) error {
+	explicit := path != ""
+	if !explicit {
+		path = config.DefaultPath()
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		if explicit || !os.IsNotExist(err) {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+	}
+
+	keys, err := update.KeyMap(cfg.Keys)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %s: %w", path, err)
+	}
+	m.KeyMap = keys
+
+	if theme == "" {
+		theme = cfg.Theme
+		if view.NoColor() {
+			theme = view.MonoTheme
+		}
+	}
+	if theme != "" {
+		if err := view.SetTheme(theme); err != nil {
+			return err
+		}
 	}
+	m.SignalPaneWidth = cfg.SignalPaneWidth
+	m.DefaultRadix = cfg.Radix
+	m.TallMode = cfg.TallMode
+	m.CursorVisible = cfg.Cursor
+	m.OverviewVisible = cfg.Overview
+	m.Run.Command = cfg.Run
+	return nil
+}
+
+// loadSession applies a sigscope session or GTKWave save file to the model.
+// GTKWave files are only read; saving writes a sigscope session next to the VCD.
+func loadSession(m *model.Model, vcdFile *waveform.VCDFile, path string) error {
+	if strings.HasSuffix(path, ".gtkw") {
+		sf, err := gtkw.Load(path)
+		if err != nil {
+			return fmt.Errorf("failed to load gtkw file: %w", err)
+		}
+		sf.ToSession(vcdFile).Apply(m)
+		m.SessionFile = session.DefaultPath(m.Filename)
+		return nil
+	}
+
+	m.SessionFile = path
+	s, err := session.Load(path)
+	if err == nil {
+		s.Apply(m)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+	return nil
 }
 
 // appModel wraps model.Model to implement tea.Model interface
@@ -72,7 +256,7 @@ type appModel struct {
 }
 
 func (a appModel) Init() tea.Cmd {
-	return watcher.WatchFile(a.Model.Filename)
+	return nil
 }
 
 func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -88,21 +272,52 @@ func printUsage() {
 	fmt.Fprintln(os.Stderr, `Usage: sigscope <command> [options] [arguments]
 
 Commands:
-  list <vcd-file>              List all signals in VCD file
+  list [OPTIONS] <vcd-file>    List all signals in VCD file
   query [OPTIONS] <vcd-file>   Query waveform data in differential event format
-  <vcd-file>                   Launch TUI viewer (default)
+  [OPTIONS] <vcd-file>         Launch TUI viewer (default)
+  [OPTIONS] --dir <directory>  Launch TUI viewer on the newest dump of a directory
+
+TUI Options:
+  -S, --session <file>         Load a session file (saved with the S key)
+                               or a GTKWave .gtkw save file
+  --config <file>              Config file with preferences and key bindings
+                               (default: $XDG_CONFIG_HOME/sigscope/config.toml)
+  --theme <name>               Color theme: dark, light, colorblind or mono
+                               (mono is the default when NO_COLOR is set)
+  --run <command>              Rebuild command run with the R key, e.g.
+                               "make sim TEST=foo"; the dump is reloaded
+                               when it succeeds (O shows its output)
+  --dir <directory>            Watch a directory of dumps instead of one file:
+                               open the newest, D picks another, N opens the
+                               next newer one keeping the view
+  -f, --follow                 Follow a dump that is still being written:
+                               read only appended data and keep the latest
+                               time in view (f pauses / resumes)
 
 Query Options:
   -s, --signals <pattern>      Signal name pattern (can be repeated)
   -t, --time-start <time>      Start time (default: 0)
   -e, --time-end <time>        End time (default: VCD end time)
 
+Common Options:
+  --no-cache                   Parse the dump even if it is cached. Parsed
+                               dumps of 1 MiB or more are kept in
+                               $XDG_CACHE_HOME/sigscope and reused while the
+                               file is unchanged (not when following)
+
 Examples:
   sigscope waveform.vcd                           # Launch TUI
+  sigscope waveform.vcd.zst                       # Compressed dumps (gzip, zstd, xz) work everywhere
+  sigscope -S debug.json waveform.vcd             # Launch TUI with a session
+  sigscope -S tb.gtkw waveform.vcd                # Launch TUI with a GTKWave save file
+  sigscope -f sim.vcd                             # Watch a running simulation
+  sigscope --run "make sim" sim.vcd               # Rerun the simulation with R
+  sigscope --dir results/                         # Browse the dumps of a regression
   sigscope list waveform.vcd                      # List all signals
   sigscope query waveform.vcd                     # Query all signals
   sigscope query -s clk -s data waveform.vcd      # Query specific signals
   sigscope query -t 1000 -e 5000 waveform.vcd     # Query time range
+  vvp sim | sigscope query -s clk -               # Query a dump piped in on stdin
 
 Use "sigscope <command> --help" for more information about a command.`)
 }
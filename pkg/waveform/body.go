@@ -0,0 +1,309 @@
+package waveform
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// The value change section, which is nearly all of a large dump, is parsed
+// in parallel: it is cut into chunks that start at a "#time" line, worker
+// goroutines tokenize the chunks into per-signal changes, and the results
+// are appended to the signals in file order. A chunk is tokenized as if it
+// started outside a $comment; in the rare case that it starts inside one,
+// it is tokenized again while merging, once that is known.
+
+// bodyChunk is the size of the chunks the value changes are cut into
+var bodyChunk = 4 << 20
+
+// chunk is a piece of the value change section ending at a line end
+type chunk struct {
+	seq  int
+	data []byte
+}
+
+// chunkResult holds the changes found in one chunk
+type chunkResult struct {
+	chunk   chunk
+	signals []chunkChanges
+	timed   bool   // Some timestamp was found
+	last    uint64 // Last timestamp
+	end     uint64 // Largest timestamp
+	comment bool   // Ends inside a $comment
+}
+
+// chunkChanges holds the changes of one signal in a chunk. Changes before
+// the chunk's first timestamp get the time in effect where it starts, which
+// is only known once the chunks before it are merged.
+type chunkChanges struct {
+	signal  int
+	untimed int
+	changes []ValueChange
+}
+
+// parseBody parses value changes from r until its end and returns the
+// number of bytes parsed. If complete is set, a last line without a newline
+// is left unparsed.
+func (p *parser) parseBody(r io.Reader, complete bool) (int64, error) {
+	workers := runtime.GOMAXPROCS(0)
+	jobs := make(chan chunk, workers)
+	results := make(chan chunkResult, workers)
+
+	var consumed int64
+	var readErr error
+	go func() {
+		consumed, readErr = readChunks(r, complete, jobs)
+		close(jobs)
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t := newTokenizer(p.index, len(p.signals))
+			for c := range jobs {
+				results <- t.parse(c, false)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Merge in file order as the chunks are done
+	pending := make(map[int]chunkResult)
+	next := 0
+	var retokenizer *tokenizer
+	for res := range results {
+		pending[res.chunk.seq] = res
+		for {
+			res, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			if p.inComment {
+				if retokenizer == nil {
+					retokenizer = newTokenizer(p.index, len(p.signals))
+				}
+				res = retokenizer.parse(res.chunk, true)
+			}
+			p.merge(res)
+			next++
+		}
+	}
+	return consumed, readErr
+}
+
+// readChunks cuts r into chunks, preferably right before a "#time" line,
+// and returns the number of bytes sent
+func readChunks(r io.Reader, complete bool, jobs chan<- chunk) (int64, error) {
+	var consumed int64
+	var carry []byte
+	for seq := 0; ; {
+		buf := make([]byte, len(carry)+bodyChunk)
+		copy(buf, carry)
+		n, err := io.ReadFull(r, buf[len(carry):])
+		buf = buf[:len(carry)+n]
+
+		eof := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !eof {
+			return consumed, fmt.Errorf("error reading file: %w", err)
+		}
+
+		cut := len(buf)
+		if !eof {
+			cut = chunkEnd(buf)
+		} else if complete {
+			cut = bytes.LastIndexByte(buf, '\n') + 1
+		}
+		if cut > 0 {
+			jobs <- chunk{seq: seq, data: buf[:cut]}
+			seq++
+			consumed += int64(cut)
+		}
+		if eof {
+			return consumed, nil
+		}
+		carry = buf[cut:]
+	}
+}
+
+// chunkEnd returns where to end a chunk: after the last line end before a
+// timestamp, else after the last line end, else 0 for none
+func chunkEnd(buf []byte) int {
+	if i := bytes.LastIndex(buf, []byte("\n#")); i >= 0 {
+		return i + 1
+	}
+	return bytes.LastIndexByte(buf, '\n') + 1
+}
+
+// merge appends the changes of the next chunk to the signals
+func (p *parser) merge(res chunkResult) {
+	for _, c := range res.signals {
+		for i := range c.untimed {
+			c.changes[i].Time = p.currentTime
+		}
+		sig := p.signals[c.signal]
+		if len(sig.Changes) == 0 {
+			sig.Changes = c.changes
+		} else {
+			sig.Changes = append(sig.Changes, c.changes...)
+		}
+	}
+	p.inComment = res.comment
+	if res.timed {
+		p.currentTime = res.last
+		if res.end > p.vcd.EndTime {
+			p.vcd.EndTime = res.end
+		}
+	}
+}
+
+// tokenizer splits chunks of value changes into whitespace-separated
+// tokens, working on the bytes so that only bus values are allocated
+type tokenizer struct {
+	index   map[string]int
+	changes [][]ValueChange // Changes in the current chunk, by signal
+	untimed []int           // Changes before the chunk's first timestamp
+	touched []int           // Signals with changes in the current chunk
+}
+
+func newTokenizer(index map[string]int, signals int) *tokenizer {
+	return &tokenizer{
+		index:   index,
+		changes: make([][]ValueChange, signals),
+		untimed: make([]int, signals),
+	}
+}
+
+// parse returns the changes in a chunk, which starts inside a $comment if
+// comment is set
+func (t *tokenizer) parse(c chunk, comment bool) chunkResult {
+	res := chunkResult{chunk: c}
+	data := c.data
+	var time uint64
+
+	for pos := 0; ; {
+		tok, next := token(data, pos)
+		if tok == nil {
+			break
+		}
+		pos = next
+
+		if comment {
+			comment = !bytes.Equal(tok, []byte("$end"))
+			continue
+		}
+
+		switch tok[0] {
+		case '#':
+			if v, ok := parseTime(tok[1:]); ok {
+				time = v
+				res.timed = true
+				res.last = v
+				res.end = max(res.end, v)
+			}
+		case 'b', 'B':
+			// Bus value, then the identifier
+			id, next := token(data, pos)
+			pos = next
+			if id != nil {
+				t.add(id, string(tok[1:]), time, res.timed)
+			}
+		case 'r', 'R':
+			// Real values are not supported; skip the identifier
+			_, pos = token(data, pos)
+		case '$':
+			comment = bytes.Equal(tok, []byte("$comment"))
+		default:
+			if value := scalarValue(tok[0]); value != "" && len(tok) >= 2 {
+				t.add(tok[1:], value, time, res.timed)
+			}
+		}
+	}
+
+	res.comment = comment
+	res.signals = make([]chunkChanges, 0, len(t.touched))
+	for _, i := range t.touched {
+		res.signals = append(res.signals, chunkChanges{signal: i, untimed: t.untimed[i], changes: t.changes[i]})
+		t.changes[i] = nil
+		t.untimed[i] = 0
+	}
+	t.touched = t.touched[:0]
+	return res
+}
+
+// add records a change of the signal with the given identifier, if any
+func (t *tokenizer) add(id []byte, value string, time uint64, timed bool) {
+	i, ok := t.index[string(id)]
+	if !ok {
+		return
+	}
+	if t.changes[i] == nil {
+		t.touched = append(t.touched, i)
+	}
+	t.changes[i] = append(t.changes[i], ValueChange{Time: time, Value: value})
+	if !timed {
+		t.untimed[i]++
+	}
+}
+
+// token returns the whitespace-separated token at or after pos and the
+// position after it, or nil at the end of data
+func token(data []byte, pos int) ([]byte, int) {
+	for pos < len(data) && isSpace(data[pos]) {
+		pos++
+	}
+	start := pos
+	for pos < len(data) && !isSpace(data[pos]) {
+		pos++
+	}
+	if start == pos {
+		return nil, pos
+	}
+	return data[start:pos], pos
+}
+
+// scalarValue returns the value of a single-bit change starting with c,
+// shared by all such changes, or "" if c is not one
+func scalarValue(c byte) string {
+	switch c {
+	case '0':
+		return "0"
+	case '1':
+		return "1"
+	case 'x', 'X':
+		return "x"
+	case 'z', 'Z':
+		return "z"
+	}
+	return ""
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\v' || c == '\f'
+}
+
+// parseTime parses the decimal digits of a timestamp
+func parseTime(digits []byte) (uint64, bool) {
+	if len(digits) == 0 {
+		return 0, false
+	}
+	var v uint64
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		d := uint64(c - '0')
+		if v > (^uint64(0)-d)/10 {
+			return 0, false
+		}
+		v = v*10 + d
+	}
+	return v, true
+}
@@ -0,0 +1,170 @@
+package waveform
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// referenceParse parses the value changes of a dump one token after the
+// other, the way the chunked parser must, to compare it with
+func referenceParse(t *testing.T, dump string) *VCDFile {
+	t.Helper()
+	i := strings.Index(dump, "$enddefinitions $end\n") + len("$enddefinitions $end\n")
+	v, err := ParseReader(strings.NewReader(dump[:i]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var time uint64
+	var comment bool
+	add := func(id, value string) {
+		if sig, ok := v.Signals[id]; ok {
+			sig.Changes = append(sig.Changes, ValueChange{Time: time, Value: value})
+		}
+	}
+	tokens := strings.Fields(dump[i:])
+	for k := 0; k < len(tokens); k++ {
+		tok := tokens[k]
+		if comment {
+			comment = tok != "$end"
+			continue
+		}
+		switch tok[0] {
+		case '#':
+			if ts, err := strconv.ParseUint(tok[1:], 10, 64); err == nil {
+				time = ts
+				v.EndTime = max(v.EndTime, ts)
+			}
+		case 'b', 'B':
+			if k+1 < len(tokens) {
+				k++
+				add(tokens[k], tok[1:])
+			}
+		case 'r', 'R':
+			k++
+		case '$':
+			comment = tok == "$comment"
+		case '0', '1', 'x', 'X', 'z', 'Z':
+			if len(tok) >= 2 {
+				add(tok[1:], strings.ToLower(tok[:1]))
+			}
+		}
+	}
+	return v
+}
+
+// randomBody returns value changes with comments, dump blocks, several
+// changes per line, unknown identifiers and the odd timestamp going back
+func randomBody(rng *rand.Rand, lines int) string {
+	ids := []string{"!", "#", `"`, "a#1", "?"}
+	scalar := func() string { return string("01xXzZ"[rng.Intn(6)]) }
+	bus := func() string {
+		b := make([]byte, 1+rng.Intn(8))
+		for i := range b {
+			b[i] = "0101xz"[rng.Intn(6)]
+		}
+		return "b" + string(b)
+	}
+
+	var sb strings.Builder
+	var time int
+	for range lines {
+		switch rng.Intn(12) {
+		case 0, 1:
+			time += rng.Intn(20)
+			if rng.Intn(20) == 0 {
+				time = max(0, time-50)
+			}
+			fmt.Fprintf(&sb, "#%d\n", time)
+		case 2:
+			// Comments with lines that would parse as changes
+			sb.WriteString("$comment\n")
+			for range rng.Intn(6) {
+				fmt.Fprintf(&sb, "#%d\n1!\n%s \"\n", rng.Intn(1000), bus())
+			}
+			sb.WriteString("$end\n")
+		case 3:
+			fmt.Fprintf(&sb, "$comment #%d 0! $end\n", rng.Intn(1000))
+		case 4:
+			fmt.Fprintf(&sb, "$dumpvars\n%s!\n%s \"\n$end\n", scalar(), bus())
+		case 5:
+			fmt.Fprintf(&sb, "%s%s %s%s\r\n", scalar(), ids[rng.Intn(len(ids))], scalar(), ids[rng.Intn(len(ids))])
+		case 6:
+			fmt.Fprintf(&sb, "r%d.5 %s\n\n", rng.Intn(10), ids[rng.Intn(len(ids))])
+		case 7, 8:
+			fmt.Fprintf(&sb, "%s %s\n", bus(), ids[rng.Intn(len(ids))])
+		default:
+			fmt.Fprintf(&sb, "%s%s\n", scalar(), ids[rng.Intn(len(ids))])
+		}
+	}
+	return sb.String()
+}
+
+// withChunkSize parses with value change chunks of the given size
+func withChunkSize(t *testing.T, size int, dump string) *VCDFile {
+	t.Helper()
+	defer func(saved int) { bodyChunk = saved }(bodyChunk)
+	bodyChunk = size
+	v, err := ParseReader(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestParseChunked(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := range 200 {
+		dump := testHeader + randomBody(rng, 1+rng.Intn(60))
+		want := referenceParse(t, dump)
+		for _, size := range []int{1, 2, 3, 5, 8, 13, 32, 100, 4 << 20} {
+			if diff := equalVCD(withChunkSize(t, size, dump), want); diff != "" {
+				t.Fatalf("dump %d, chunks of %d bytes: %s\n%s", n, size, diff, dump)
+			}
+		}
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		clk  []ValueChange
+		end  uint64
+	}{
+		{
+			name: "one line",
+			body: "#0\n0!\n$comment #5 1! $end\n#10\n1!\n",
+			clk:  []ValueChange{{0, "0"}, {10, "1"}},
+			end:  10,
+		},
+		{
+			name: "timestamps inside",
+			body: "#0\n0!\n$comment\n#5\n1!\n#7\n$end\n#10\n1!\n",
+			clk:  []ValueChange{{0, "0"}, {10, "1"}},
+			end:  10,
+		},
+		{
+			name: "$end ends the comment only",
+			body: "#0\n$comment\n#3\n$end 1!\n#4\n0!\n",
+			clk:  []ValueChange{{0, "1"}, {4, "0"}},
+			end:  4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, size := range []int{1, 4, 4 << 20} {
+				v := withChunkSize(t, size, testHeader+tt.body)
+				if got := v.Lookup("top.clk").Changes; fmt.Sprint(got) != fmt.Sprint(tt.clk) {
+					t.Errorf("chunks of %d bytes: clk %v, want %v", size, got, tt.clk)
+				}
+				if v.EndTime != tt.end {
+					t.Errorf("chunks of %d bytes: end time %d, want %d", size, v.EndTime, tt.end)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package waveform
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cache keeps parsed dumps in a directory, so that opening a dump that has
+// not changed since skips parsing it. A dump counts as unchanged if its
+// path, size and modification time are the same and so is a hash of all of
+// its content. Hashing reads the whole dump, but at disk speed, which is
+// still many times faster than parsing it; a hash of only some of it would
+// miss a dump rewritten in place within the timestamp resolution. The
+// least recently used cache files are removed to keep the directory under
+// MaxSize. A nil *Cache parses every time.
+type Cache struct {
+	Dir     string
+	MaxSize int64 // Total size of the cache files kept, cacheMaxSize if 0
+}
+
+// cacheMinSize is the size from which dumps are cached; smaller ones parse
+// about as fast as their cache would load
+const cacheMinSize = 1 << 20
+
+// cacheMaxSize is the default bound on the total size of the cache files
+const cacheMaxSize = 2 << 30
+
+// crcTable hashes the content of dumps, checked before a cache is used
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// cacheKey identifies the dump a cache file was made from
+type cacheKey struct {
+	path    string // Absolute path
+	size    int64
+	modTime int64 // Unix nanoseconds
+	hash    uint32
+}
+
+// DefaultCache returns the cache in the user's cache directory, i.e.
+// $XDG_CACHE_HOME/sigscope or ~/.cache/sigscope on Linux, or nil if there
+// is none
+func DefaultCache() *Cache {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	return &Cache{Dir: filepath.Join(dir, "sigscope")}
+}
+
+// Parse returns the parsed dump like Parse, from the cache if it holds the
+// file as it is now. Otherwise the file is parsed and the result stored
+// for next time; failing to read or write the cache is not an error.
+func (c *Cache) Parse(filename string) (*VCDFile, error) {
+	if c == nil {
+		return Parse(filename)
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return Parse(filename)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return Parse(filename)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.Size() < cacheMinSize || !info.Mode().IsRegular() {
+		return Parse(filename)
+	}
+	key := cacheKey{path: abs, size: info.Size(), modTime: info.ModTime().UnixNano()}
+	path := c.path(abs)
+
+	if v, ok := c.load(path, key, f); ok {
+		return v, nil
+	}
+
+	// Hash what is parsed, so the key matches the content of the cache
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return Parse(filename)
+	}
+	h := crc32.New(crcTable)
+	v, err := ParseReader(io.TeeReader(f, h))
+	if err != nil {
+		return nil, err
+	}
+	key.hash = h.Sum32()
+
+	// A dump written to while it was parsed is not cached
+	if now, err := os.Stat(filename); err == nil && os.SameFile(info, now) &&
+		now.Size() == key.size && now.ModTime().UnixNano() == key.modTime {
+		c.store(path, key, v)
+	}
+	return v, nil
+}
+
+// path returns the cache file of the dump at an absolute path
+func (c *Cache) path(abs string) string {
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:16])+cacheSuffix)
+}
+
+// cacheSuffix ends the names of cache files
+const cacheSuffix = ".cache"
+
+// load reads a cache file if it was made from the dump open in f as it is now
+func (c *Cache) load(path string, key cacheKey, f *os.File) (*VCDFile, bool) {
+	cf, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer cf.Close()
+	info, err := cf.Stat()
+	if err != nil {
+		return nil, false
+	}
+
+	d := &decoder{r: bufio.NewReaderSize(cf, 256*1024), limit: info.Size()}
+	stored := d.key()
+	if d.err != nil || stored.path != key.path || stored.size != key.size || stored.modTime != key.modTime {
+		return nil, false
+	}
+	// Same name, size and time; check the content, too
+	hash, err := hashFile(f)
+	if err != nil || hash != stored.hash {
+		return nil, false
+	}
+
+	v := d.vcd()
+	if d.err != nil {
+		return nil, false
+	}
+	// Mark it used for pruning
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return v, true
+}
+
+// store writes a cache file, replacing any earlier one at once, and prunes
+// the cache to its size
+func (c *Cache) store(path string, key cacheKey, v *VCDFile) {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriterSize(tmp, 256*1024)
+	e := &encoder{w: w}
+	e.key(key)
+	e.vcd(v)
+	if e.err != nil || w.Flush() != nil || tmp.Close() != nil {
+		tmp.Close()
+		return
+	}
+	if os.Rename(tmp.Name(), path) == nil {
+		c.prune(path)
+	}
+}
+
+// prune removes the least recently used cache files until the rest fit in
+// MaxSize. The file just stored is kept even if it alone doesn't fit.
+func (c *Cache) prune(keep string) {
+	limit := c.MaxSize
+	if limit <= 0 {
+		limit = cacheMaxSize
+	}
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return
+	}
+	var files []os.FileInfo
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), cacheSuffix) {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			files = append(files, info)
+		}
+	}
+	// Newest first; the one just stored always stays
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().After(files[j].ModTime())
+	})
+	var total int64
+	for _, info := range files {
+		path := filepath.Join(c.Dir, info.Name())
+		total += info.Size()
+		if total > limit && path != keep {
+			os.Remove(path)
+		}
+	}
+}
+
+// hashFile hashes all of an open file
+func hashFile(f *os.File) (uint32, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	h := crc32.New(crcTable)
+	if _, err := io.Copy(h, f); err != nil {
+		return 0, err
+	}
+	return h.Sum32(), nil
+}
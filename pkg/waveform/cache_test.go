@@ -0,0 +1,302 @@
+package waveform
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// encodeCache returns a cache file's bytes
+func encodeCache(t *testing.T, key cacheKey, v *VCDFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	e := &encoder{w: w}
+	e.key(key)
+	e.vcd(v)
+	if e.err != nil || w.Flush() != nil {
+		t.Fatalf("encoding failed: %v", e.err)
+	}
+	return buf.Bytes()
+}
+
+// decodeCache reads a cache file's bytes
+func decodeCache(data []byte) (cacheKey, *VCDFile, error) {
+	d := &decoder{r: bufio.NewReader(bytes.NewReader(data)), limit: int64(len(data))}
+	key := d.key()
+	v := d.vcd()
+	return key, v, d.err
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random, err := ParseReader(strings.NewReader(testHeader + randomBody(rng, 300)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Times going back, repeated values and a signal without changes
+	handmade := NewVCDFile()
+	handmade.Version = "Icarus Verilog"
+	handmade.Date = "today"
+	handmade.Timescale = "10ps"
+	handmade.EndTime = 1 << 40
+	handmade.Signals["!"] = &SignalData{
+		Signal:  Signal{ID: "!", Name: "clk", Width: 1, Scope: "top", FullName: "top.clk"},
+		Changes: []ValueChange{{0, "0"}, {5, "1"}, {3, "0"}, {3, "0"}, {1 << 40, "x"}},
+	}
+	handmade.Signals[`"`] = &SignalData{
+		Signal:  Signal{ID: `"`, Name: "data", Width: 4, FullName: "data"},
+		Changes: []ValueChange{},
+	}
+
+	key := cacheKey{path: "/tmp/sim.vcd", size: 12345, modTime: time.Now().UnixNano(), hash: 0xdeadbeef}
+	for _, v := range []*VCDFile{random, handmade, NewVCDFile()} {
+		data := encodeCache(t, key, v)
+		gotKey, got, err := decodeCache(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotKey != key {
+			t.Errorf("key %+v, want %+v", gotKey, key)
+		}
+		if diff := equalVCD(got, v); diff != "" {
+			t.Errorf("decoded dump: %s", diff)
+		}
+
+		// Every cut short file is rejected
+		for n := range len(data) {
+			if _, _, err := decodeCache(data[:n]); err == nil {
+				t.Fatalf("file cut to %d of %d bytes decoded", n, len(data))
+			}
+		}
+	}
+}
+
+func TestCacheRejectsInvalid(t *testing.T) {
+	key := cacheKey{path: "/tmp/sim.vcd", size: 1}
+	good := encodeCache(t, key, NewVCDFile())
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"other magic", bytes.Replace(good, []byte(cacheMagic), []byte("sigscope-cachf"), 1)},
+		{"other version", bytes.Replace(good, []byte(cacheMagic+"\x01"), []byte(cacheMagic+"\x02"), 1)},
+		{"huge count", append(append([]byte{}, good[:len(good)-1]...), 0xff, 0xff, 0xff, 0x7f)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := decodeCache(tt.data); err == nil {
+				t.Error("decoded")
+			}
+		})
+	}
+}
+
+// bigDump returns a dump large enough to be cached
+func bigDump() string {
+	var sb strings.Builder
+	sb.WriteString(testHeader)
+	for i := 0; sb.Len() < cacheMinSize+cacheMinSize/4; i++ {
+		fmt.Fprintf(&sb, "#%d\n%d!\nb%b \"\n", i*10, i%2, i%16)
+	}
+	return sb.String()
+}
+
+// cached reports whether the cache holds the file as it is now
+func cached(t *testing.T, c *Cache, filename string) bool {
+	t.Helper()
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	key := cacheKey{path: abs, size: info.Size(), modTime: info.ModTime().UnixNano()}
+	_, ok := c.load(c.path(abs), key, f)
+	return ok
+}
+
+// rewrite replaces old with new in a file, keeping its modification time
+func rewrite(t *testing.T, path, old, new string) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := strings.LastIndex(string(data), old)
+	if i < 0 || len(old) != len(new) {
+		t.Fatalf("can't replace %q", old)
+	}
+	copy(data[i:], new)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCacheParse(t *testing.T) {
+	dump := bigDump()
+	last := dump[strings.LastIndex(dump[:len(dump)-1], "\n")+1:]
+	// A time and the clock change after it, halfway through
+	middle := dump[len(dump)/2:]
+	middle = middle[strings.Index(middle, "\n#")+1:]
+	middle = middle[:strings.Index(middle, "!")+1]
+
+	tests := []struct {
+		name   string
+		change func(t *testing.T, path string, c *Cache)
+		stale  bool
+	}{
+		{"unchanged", func(*testing.T, string, *Cache) {}, false},
+		{"appended", func(t *testing.T, path string, _ *Cache) {
+			appendFile(t, path, "#99999999\n1!\n")
+		}, true},
+		{"touched", func(t *testing.T, path string, _ *Cache) {
+			later := time.Now().Add(time.Hour)
+			if err := os.Chtimes(path, later, later); err != nil {
+				t.Fatal(err)
+			}
+		}, true},
+		{"head rewritten in place", func(t *testing.T, path string, _ *Cache) {
+			rewrite(t, path, "$timescale 1ns", "$timescale 1ps")
+		}, true},
+		{"tail rewritten in place", func(t *testing.T, path string, _ *Cache) {
+			rewrite(t, path, last, strings.Replace(last, `"`, "#", 1))
+		}, true},
+		{"middle rewritten in place", func(t *testing.T, path string, _ *Cache) {
+			rewrite(t, path, middle, strings.Replace(middle, "!", "#", 1))
+		}, true},
+		{"cache file cut short", func(t *testing.T, path string, c *Cache) {
+			abs, _ := filepath.Abs(path)
+			if err := os.Truncate(c.path(abs), 1000); err != nil {
+				t.Fatal(err)
+			}
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Cache{Dir: t.TempDir()}
+			path := writeFile(t, "sim.vcd", dump)
+			if _, err := c.Parse(path); err != nil {
+				t.Fatal(err)
+			}
+			if !cached(t, c, path) {
+				t.Fatal("not cached after parsing")
+			}
+
+			tt.change(t, path, c)
+			if got := cached(t, c, path); got == tt.stale {
+				t.Errorf("cache used: %v, want %v", got, !tt.stale)
+			}
+
+			got, err := c.Parse(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := Parse(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := equalVCD(got, want); diff != "" {
+				t.Errorf("parsed through the cache: %s", diff)
+			}
+			if !cached(t, c, path) {
+				t.Error("not cached again")
+			}
+		})
+	}
+}
+
+func TestCacheSkipsSmallFiles(t *testing.T) {
+	c := &Cache{Dir: filepath.Join(t.TempDir(), "cache")}
+	path := writeFile(t, "sim.vcd", testHeader+"#0\n1!\n")
+	if _, err := c.Parse(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(c.Dir); !os.IsNotExist(err) {
+		t.Errorf("cache directory created for a small file")
+	}
+
+	var nilCache *Cache
+	if _, err := nilCache.Parse(path); err != nil {
+		t.Errorf("nil cache: %v", err)
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	dump := bigDump()
+	c := &Cache{Dir: t.TempDir(), MaxSize: 1}
+	var paths []string
+	for i := range 3 {
+		paths = append(paths, writeFile(t, fmt.Sprintf("sim%d.vcd", i), dump))
+	}
+
+	// Each cache file alone is over the limit, so only the last one stays
+	for _, path := range paths {
+		if _, err := c.Parse(path); err != nil {
+			t.Fatal(err)
+		}
+		if !cached(t, c, path) {
+			t.Fatalf("%s not cached", path)
+		}
+	}
+	for _, path := range paths[:2] {
+		if cached(t, c, path) {
+			t.Errorf("%s still cached", path)
+		}
+	}
+
+	// Room for two: using a cache file keeps it over an older one
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("%d files in the cache, %v", len(entries), err)
+	}
+	info, err := entries[0].Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.MaxSize = 2 * info.Size()
+	if _, err := c.Parse(paths[0]); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	for i, path := range []string{paths[0], paths[2]} {
+		abs, _ := filepath.Abs(path)
+		stored := old.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(c.path(abs), stored, stored); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !cached(t, c, paths[0]) { // Used now, after sim2.vcd
+		t.Fatal("cache of sim0.vcd not kept")
+	}
+	if _, err := c.Parse(paths[1]); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []bool{true, true, false} {
+		if got := cached(t, c, paths[i]); got != want {
+			t.Errorf("sim%d.vcd cached %v, want %v", i, got, want)
+		}
+	}
+}
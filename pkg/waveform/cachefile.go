@@ -0,0 +1,220 @@
+package waveform
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// A cache file holds, after a magic number and format version, the key of
+// the dump it was made from and then the parsed dump: the header strings,
+// the end time and the signals. Each signal's changes are stored as time
+// deltas and indices into a table of its distinct values, all varints, so
+// that repeated values are stored and loaded once.
+
+// cacheMagic starts every cache file
+const cacheMagic = "sigscope-cache"
+
+// cacheVersion changes whenever the format does, making older files misses
+const cacheVersion = 1
+
+// errCacheFormat reports a cache file that is not in the expected format
+var errCacheFormat = errors.New("invalid cache file")
+
+// encoder writes a cache file, keeping the first error
+type encoder struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (e *encoder) uvarint(v uint64) {
+	if e.err == nil {
+		_, e.err = e.w.Write(e.buf[:binary.PutUvarint(e.buf[:], v)])
+	}
+}
+
+func (e *encoder) varint(v int64) {
+	if e.err == nil {
+		_, e.err = e.w.Write(e.buf[:binary.PutVarint(e.buf[:], v)])
+	}
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	if e.err == nil {
+		_, e.err = e.w.WriteString(s)
+	}
+}
+
+func (e *encoder) key(k cacheKey) {
+	e.string(cacheMagic)
+	e.uvarint(cacheVersion)
+	e.string(k.path)
+	e.varint(k.size)
+	e.varint(k.modTime)
+	e.uvarint(uint64(k.hash))
+}
+
+func (e *encoder) vcd(v *VCDFile) {
+	e.string(v.Version)
+	e.string(v.Date)
+	e.string(v.Timescale)
+	e.uvarint(v.EndTime)
+
+	e.uvarint(uint64(len(v.Signals)))
+	for _, sd := range v.Signals {
+		sig := sd.Signal
+		e.string(sig.ID)
+		e.string(sig.Name)
+		e.varint(int64(sig.Width))
+		e.string(sig.Scope)
+		e.string(sig.FullName)
+		e.changes(sd.Changes)
+	}
+}
+
+func (e *encoder) changes(changes []ValueChange) {
+	index := make(map[string]uint64)
+	var values []string
+	for _, c := range changes {
+		if _, ok := index[c.Value]; !ok {
+			index[c.Value] = uint64(len(values))
+			values = append(values, c.Value)
+		}
+	}
+
+	e.uvarint(uint64(len(values)))
+	for _, value := range values {
+		e.string(value)
+	}
+	e.uvarint(uint64(len(changes)))
+	var last uint64
+	for _, c := range changes {
+		if c.Time >= 1<<63 {
+			// No room for the flag bit; such a dump is not cached
+			e.err = errCacheFormat
+			return
+		}
+		// Times only go back in broken dumps; store those as they are
+		if c.Time >= last {
+			e.uvarint((c.Time - last) << 1)
+		} else {
+			e.uvarint(c.Time<<1 | 1)
+		}
+		last = c.Time
+		e.uvarint(index[c.Value])
+	}
+}
+
+// decoder reads a cache file, keeping the first error. No count can exceed
+// the file size, which guards against allocating for a corrupt file.
+type decoder struct {
+	r     *bufio.Reader
+	limit int64
+	err   error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	d.err = err
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.r)
+	d.err = err
+	return v
+}
+
+// count reads the number of items that follow
+func (d *decoder) count() int {
+	n := d.uvarint()
+	if d.err == nil && n > uint64(d.limit) {
+		d.err = errCacheFormat
+	}
+	if d.err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) string() string {
+	n := d.count()
+	if d.err != nil {
+		return ""
+	}
+	buf := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, buf)
+	return string(buf)
+}
+
+func (d *decoder) key() cacheKey {
+	if d.string() != cacheMagic || d.uvarint() != cacheVersion {
+		if d.err == nil {
+			d.err = errCacheFormat
+		}
+		return cacheKey{}
+	}
+	return cacheKey{
+		path:    d.string(),
+		size:    d.varint(),
+		modTime: d.varint(),
+		hash:    uint32(d.uvarint()),
+	}
+}
+
+func (d *decoder) vcd() *VCDFile {
+	v := NewVCDFile()
+	v.Version = d.string()
+	v.Date = d.string()
+	v.Timescale = d.string()
+	v.EndTime = d.uvarint()
+
+	n := d.count()
+	for i := 0; i < n && d.err == nil; i++ {
+		var sig Signal
+		sig.ID = d.string()
+		sig.Name = d.string()
+		sig.Width = int(d.varint())
+		sig.Scope = d.string()
+		sig.FullName = d.string()
+		v.Signals[sig.ID] = &SignalData{Signal: sig, Changes: d.changes()}
+	}
+	return v
+}
+
+func (d *decoder) changes() []ValueChange {
+	values := make([]string, d.count())
+	for i := range values {
+		values[i] = d.string()
+	}
+
+	changes := make([]ValueChange, d.count())
+	var last uint64
+	for i := range changes {
+		delta := d.uvarint()
+		if delta&1 == 0 {
+			last += delta >> 1
+		} else {
+			last = delta >> 1
+		}
+		j := d.uvarint()
+		if d.err != nil {
+			return nil
+		}
+		if j >= uint64(len(values)) {
+			d.err = errCacheFormat
+			return nil
+		}
+		changes[i] = ValueChange{Time: last, Value: values[j]}
+	}
+	return changes
+}
@@ -0,0 +1,112 @@
+package waveform
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Magic bytes at the start of compressed files
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	xzMagic   = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+)
+
+// Open opens a VCD file for reading. Files compressed with gzip, zstd or
+// xz are recognized by their magic bytes, whatever their name, and
+// decompressed on the fly.
+func Open(filename string) (io.ReadCloser, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	r, err := decompress(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return readCloser{r, func() error {
+		r.Close()
+		return file.Close()
+	}}, nil
+}
+
+// IsCompressed reports whether a file starts with the magic bytes of a
+// supported compression format
+func IsCompressed(filename string) (bool, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	head := make([]byte, len(xzMagic))
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, err
+	}
+	return compression(head[:n]) != "", nil
+}
+
+// compression names the format whose magic bytes start head, or ""
+func compression(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		return "gzip"
+	case bytes.HasPrefix(head, zstdMagic):
+		return "zstd"
+	case bytes.HasPrefix(head, xzMagic):
+		return "xz"
+	}
+	return ""
+}
+
+// decompress wraps r in a decompressor if its content is compressed.
+// Closing the result releases the decompressor, not r.
+func decompress(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(len(xzMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch compression(head) {
+	case "gzip":
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("gzip: %w", err)
+		}
+		return zr, nil
+	case "zstd":
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("zstd: %w", err)
+		}
+		return readCloser{zr, func() error { zr.Close(); return nil }}, nil
+	case "xz":
+		zr, err := xz.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("xz: %w", err)
+		}
+		return io.NopCloser(zr), nil
+	}
+	return io.NopCloser(br), nil
+}
+
+// readCloser pairs a reader with the function that releases it
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r readCloser) Close() error {
+	return r.close()
+}
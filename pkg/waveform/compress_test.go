@@ -0,0 +1,112 @@
+package waveform
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// compressed returns data compressed in the given format, or data as it is
+func compressed(t *testing.T, format, data string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch format {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zstd":
+		w, err = zstd.NewWriter(&buf)
+	case "xz":
+		w, err = xz.NewWriter(&buf)
+	default:
+		return data
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestOpen(t *testing.T) {
+	dump := testHeader + "#0\n1!\n"
+	tests := []struct {
+		format string
+		data   string
+	}{
+		{"", dump},
+		{"", ""},
+		{"", "\x1f"}, // Shorter than any magic
+		{"gzip", dump},
+		{"zstd", dump},
+		{"xz", dump},
+		{"gzip", ""},
+	}
+	for _, tt := range tests {
+		// Names don't matter, only content does
+		path := writeFile(t, "sim.vcd", compressed(t, tt.format, tt.data))
+
+		got, err := IsCompressed(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != (tt.format != "") {
+			t.Errorf("%s %q: compressed %v", tt.format, tt.data, got)
+		}
+
+		r, err := Open(path)
+		if err != nil {
+			t.Fatalf("%s %q: %v", tt.format, tt.data, err)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Errorf("%s %q: %v", tt.format, tt.data, err)
+		}
+		if err := r.Close(); err != nil {
+			t.Errorf("%s %q: closing: %v", tt.format, tt.data, err)
+		}
+		if string(data) != tt.data {
+			t.Errorf("%s: read %q, want %q", tt.format, data, tt.data)
+		}
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	if _, err := Open("/nonexistent/sim.vcd"); err == nil {
+		t.Error("opened a missing file")
+	}
+	if _, err := IsCompressed("/nonexistent/sim.vcd"); err == nil {
+		t.Error("sniffed a missing file")
+	}
+
+	// Magic bytes followed by garbage
+	tests := []struct {
+		format string
+		data   string
+	}{
+		{"gzip", "\x1f\x8b\x00garbage"},
+		{"xz", "\xfd7zXZ\x00garbage"},
+		{"zstd", "\x28\xb5\x2f\xfdgarbage"},
+	}
+	for _, tt := range tests {
+		path := writeFile(t, "sim.vcd", tt.data)
+		r, err := Open(path)
+		if err == nil {
+			_, err = io.ReadAll(r)
+			r.Close()
+		}
+		if err == nil {
+			t.Errorf("%s garbage read without an error", tt.format)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package waveform
+
+import "sort"
+
+// The changes of a signal are in time order, as in the dump, so they serve
+// as their own time index: lookups binary search them rather than scanning
+// from the start, and a Cursor walks them forward from any time.
+
+// search returns the index of the first change at or after time t
+func (sd *SignalData) search(t uint64) int {
+	return sort.Search(len(sd.Changes), func(i int) bool {
+		return sd.Changes[i].Time >= t
+	})
+}
+
+// searchAfter returns the index of the first change after time t
+func (sd *SignalData) searchAfter(t uint64) int {
+	return sort.Search(len(sd.Changes), func(i int) bool {
+		return sd.Changes[i].Time > t
+	})
+}
+
+// NextChange returns the first change after time t
+func (sd *SignalData) NextChange(t uint64) (ValueChange, bool) {
+	i := sd.searchAfter(t)
+	if i == len(sd.Changes) {
+		return ValueChange{}, false
+	}
+	return sd.Changes[i], true
+}
+
+// PrevChange returns the last change before time t
+func (sd *SignalData) PrevChange(t uint64) (ValueChange, bool) {
+	i := sd.search(t)
+	if i == 0 {
+		return ValueChange{}, false
+	}
+	return sd.Changes[i-1], true
+}
+
+// Cursor iterates over the changes of a signal in time order. Seeking
+// forward to a nearby time, as when walking a time window column by
+// column, costs no more than the changes passed over.
+type Cursor struct {
+	sd  *SignalData
+	pos int // Index of the next change
+}
+
+// Cursor returns a cursor positioned at time t
+func (sd *SignalData) Cursor(t uint64) *Cursor {
+	return &Cursor{sd: sd, pos: sd.search(t)}
+}
+
+// Seek positions the cursor at time t: Next returns the changes from t on,
+// and Value the value just before t
+func (c *Cursor) Seek(t uint64) {
+	changes := c.sd.Changes
+	if c.pos > 0 && changes[c.pos-1].Time >= t {
+		// Backwards
+		c.pos = sort.Search(c.pos, func(i int) bool { return changes[i].Time >= t })
+		return
+	}
+	// Forwards, stepping over the few changes usually in between
+	for steps := 0; c.pos < len(changes) && changes[c.pos].Time < t; steps++ {
+		if steps == 8 {
+			rest := changes[c.pos:]
+			c.pos += sort.Search(len(rest), func(i int) bool { return rest[i].Time >= t })
+			return
+		}
+		c.pos++
+	}
+}
+
+// Value returns the value before the cursor position, "x" at the start
+func (c *Cursor) Value() string {
+	if c.pos == 0 {
+		return "x"
+	}
+	return c.sd.Changes[c.pos-1].Value
+}
+
+// Next returns the next change before time end and moves past it
+func (c *Cursor) Next(end uint64) (ValueChange, bool) {
+	if c.pos == len(c.sd.Changes) || c.sd.Changes[c.pos].Time >= end {
+		return ValueChange{}, false
+	}
+	c.pos++
+	return c.sd.Changes[c.pos-1], true
+}
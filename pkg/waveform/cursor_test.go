@@ -0,0 +1,116 @@
+package waveform
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// Changes with two at the same time, as written by a zero-width glitch
+var testChanges = &SignalData{Changes: []ValueChange{
+	{10, "0"}, {20, "1"}, {20, "x"}, {30, "0"}, {50, "1"},
+}}
+
+func TestLookups(t *testing.T) {
+	tests := []struct {
+		time  uint64
+		value string
+		next  string // Value of the next change, "" if none
+		prev  string // Value of the previous change, "" if none
+	}{
+		{0, "x", "0", ""},
+		{10, "0", "1", ""},
+		{15, "0", "1", "0"},
+		{20, "x", "0", "0"},
+		{25, "x", "0", "x"},
+		{50, "1", "", "0"},
+		{1 << 63, "1", "", "1"},
+	}
+	for _, tt := range tests {
+		if got := testChanges.GetValueAt(tt.time); got != tt.value {
+			t.Errorf("value at %d: %q, want %q", tt.time, got, tt.value)
+		}
+		if got, ok := testChanges.NextChange(tt.time); got.Value != tt.next || ok != (tt.next != "") {
+			t.Errorf("next change after %d: %v %v, want %q", tt.time, got, ok, tt.next)
+		}
+		if got, ok := testChanges.PrevChange(tt.time); got.Value != tt.prev || ok != (tt.prev != "") {
+			t.Errorf("previous change before %d: %v %v, want %q", tt.time, got, ok, tt.prev)
+		}
+	}
+}
+
+func TestChangesIn(t *testing.T) {
+	tests := []struct {
+		start, end uint64
+		want       string
+	}{
+		{0, 100, "[{10 0} {20 1} {20 x} {30 0} {50 1}]"},
+		{20, 20, "[{20 1} {20 x}]"},
+		{21, 49, "[{30 0}]"},
+		{31, 49, "[]"},
+		{60, 100, "[]"},
+		{30, 20, "[]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(testChanges.ChangesIn(tt.start, tt.end)); got != tt.want {
+			t.Errorf("changes in [%d, %d]: %s, want %s", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+// walk returns the value at start and the changes in [start, end) found
+// by scanning every change
+func walk(sd *SignalData, start, end uint64) (string, []ValueChange) {
+	value := "x"
+	var changes []ValueChange
+	for _, c := range sd.Changes {
+		if c.Time < start {
+			value = c.Value
+		} else if c.Time < end {
+			changes = append(changes, c)
+		}
+	}
+	return value, changes
+}
+
+func TestCursor(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sd := &SignalData{}
+	var time uint64
+	for range 500 {
+		// Gaps long and short, and repeated times
+		time += uint64(rng.Intn(3)) * uint64(1+rng.Intn(3)*rng.Intn(50))
+		sd.Changes = append(sd.Changes, ValueChange{time, fmt.Sprint(rng.Intn(4))})
+	}
+
+	c := sd.Cursor(0)
+	for range 2000 {
+		// Mostly small steps forward, sometimes jumps either way
+		start := time * uint64(rng.Intn(110)) / 100
+		if rng.Intn(4) > 0 {
+			start = uint64(rng.Intn(int(time) + 10))
+		}
+		end := start + uint64(rng.Intn(30))
+
+		c.Seek(start)
+		wantValue, wantChanges := walk(sd, start, end)
+		if got := c.Value(); got != wantValue {
+			t.Fatalf("value at %d: %q, want %q", start, got, wantValue)
+		}
+		var got []ValueChange
+		for change, ok := c.Next(end); ok; change, ok = c.Next(end) {
+			got = append(got, change)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(wantChanges) {
+			t.Fatalf("changes in [%d, %d): %v, want %v", start, end, got, wantChanges)
+		}
+		if fresh := sd.Cursor(start); fresh.Value() != wantValue {
+			t.Fatalf("new cursor at %d: value %q, want %q", start, fresh.Value(), wantValue)
+		}
+	}
+
+	empty := (&SignalData{}).Cursor(10)
+	if _, ok := empty.Next(100); ok || empty.Value() != "x" {
+		t.Error("cursor over no changes")
+	}
+}
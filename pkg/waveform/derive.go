@@ -0,0 +1,140 @@
+package waveform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExtendValue left-extends a bus value to the full signal width.
+// VCD omits leading bits; x and z extend as themselves, anything else as 0.
+func ExtendValue(value string, width int) string {
+	if len(value) >= width {
+		return value[len(value)-width:]
+	}
+	pad := "0"
+	if len(value) > 0 {
+		switch value[0] {
+		case 'x', 'X':
+			pad = "x"
+		case 'z', 'Z':
+			pad = "z"
+		}
+	}
+	return strings.Repeat(pad, width-len(value)) + value
+}
+
+// ExtractBit derives a 1-bit signal from one bit of a bus (bit 0 = LSB)
+func ExtractBit(sd *SignalData, bit int) *SignalData {
+	name := fmt.Sprintf("%s[%d]", sd.Signal.Name, bit)
+	fullName := name
+	if sd.Signal.Scope != "" {
+		fullName = sd.Signal.Scope + "." + name
+	}
+
+	derived := &SignalData{
+		Signal: Signal{
+			Name:     name,
+			Width:    1,
+			Scope:    sd.Signal.Scope,
+			FullName: fullName,
+		},
+		Changes: make([]ValueChange, 0),
+	}
+
+	AppendBit(derived, sd, bit, 0)
+	return derived
+}
+
+// AppendBit extends a signal derived by ExtractBit with the bit's changes
+// in sd.Changes[from:], e.g. those appended while following a dump
+func AppendBit(derived, sd *SignalData, bit, from int) {
+	pos := sd.Signal.Width - 1 - bit
+	last := ""
+	if n := len(derived.Changes); n > 0 {
+		last = derived.Changes[n-1].Value
+	}
+	for _, change := range sd.Changes[from:] {
+		value := strings.ToLower(ExtendValue(change.Value, sd.Signal.Width)[pos : pos+1])
+		if value == last {
+			continue
+		}
+		derived.Changes = append(derived.Changes, ValueChange{Time: change.Time, Value: value})
+		last = value
+	}
+}
+
+// CombineBits builds a virtual bus from 1-bit signals, listed MSB first
+func CombineBits(name string, bits []*SignalData) *SignalData {
+	combined := &SignalData{
+		Signal: Signal{
+			Name:     name,
+			Width:    len(bits),
+			FullName: name,
+		},
+		Changes: make([]ValueChange, 0),
+	}
+
+	AppendCombined(combined, bits, make([]int, len(bits)))
+	return combined
+}
+
+// AppendCombined extends a virtual bus built by CombineBits with the
+// changes in bits[i].Changes[from[i]:], e.g. those appended while
+// following a dump
+func AppendCombined(combined *SignalData, bits []*SignalData, from []int) {
+	// Collect every time at which any bit changes
+	timeSet := make(map[uint64]struct{})
+	for i, bit := range bits {
+		for _, change := range bit.Changes[from[i]:] {
+			timeSet[change.Time] = struct{}{}
+		}
+	}
+	times := make([]uint64, 0, len(timeSet))
+	for t := range timeSet {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	// Walk all bits in step, tracking each bit's current value
+	current := make([]string, len(bits))
+	next := append([]int{}, from...)
+	for i, bit := range bits {
+		current[i] = "x"
+		if from[i] > 0 {
+			current[i] = bit.Changes[from[i]-1].Value
+		}
+	}
+
+	last := ""
+	if n := len(combined.Changes); n > 0 {
+		last = combined.Changes[n-1].Value
+	}
+	var sb strings.Builder
+	for _, t := range times {
+		sb.Reset()
+		for i, bit := range bits {
+			for next[i] < len(bit.Changes) && bit.Changes[next[i]].Time <= t {
+				current[i] = bit.Changes[next[i]].Value
+				next[i]++
+			}
+			sb.WriteString(current[i])
+		}
+		value := sb.String()
+		if value == last {
+			continue
+		}
+		// Bits that changed again at the time of the last append replace it
+		if n := len(combined.Changes); n > 0 && combined.Changes[n-1].Time == t {
+			combined.Changes = combined.Changes[:n-1]
+			if last = ""; n > 1 {
+				last = combined.Changes[n-2].Value
+			}
+			if value == last {
+				continue
+			}
+		}
+		combined.Changes = append(combined.Changes, ValueChange{Time: t, Value: value})
+		last = value
+	}
+}
@@ -0,0 +1,126 @@
+package waveform
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExtendValue(t *testing.T) {
+	tests := []struct {
+		value string
+		width int
+		want  string
+	}{
+		{"1", 4, "0001"},
+		{"101", 4, "0101"},
+		{"x1", 4, "xxx1"},
+		{"X", 3, "xxX"},
+		{"z0", 4, "zzz0"},
+		{"", 2, "00"},
+		{"1010", 4, "1010"},
+		{"110101", 4, "0101"}, // Too wide: the low bits are kept
+	}
+	for _, tt := range tests {
+		if got := ExtendValue(tt.value, tt.width); got != tt.want {
+			t.Errorf("ExtendValue(%q, %d) = %q, want %q", tt.value, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestExtractBit(t *testing.T) {
+	bus := &SignalData{
+		Signal: Signal{Name: "data", Width: 4, Scope: "top", FullName: "top.data"},
+		Changes: []ValueChange{
+			{0, "x"}, {10, "1"}, {20, "1010"}, {30, "Z1"}, {40, "0011"},
+		},
+	}
+	tests := []struct {
+		bit  int
+		want string
+	}{
+		{0, "[{0 x} {10 1} {20 0} {30 1}]"},
+		{1, "[{0 x} {10 0} {20 1} {30 z} {40 1}]"},
+		{3, "[{0 x} {10 0} {20 1} {30 z} {40 0}]"}, // MSB
+	}
+	for _, tt := range tests {
+		got := ExtractBit(bus, tt.bit)
+		if s := fmt.Sprint(got.Changes); s != tt.want {
+			t.Errorf("bit %d: %s, want %s", tt.bit, s, tt.want)
+		}
+		name := fmt.Sprintf("data[%d]", tt.bit)
+		if got.Signal.Name != name || got.Signal.FullName != "top."+name || got.Signal.Width != 1 {
+			t.Errorf("bit %d: signal %+v", tt.bit, got.Signal)
+		}
+	}
+}
+
+func TestCombineBits(t *testing.T) {
+	bit := func(name string, changes ...ValueChange) *SignalData {
+		return &SignalData{Signal: Signal{Name: name, Width: 1, FullName: name}, Changes: changes}
+	}
+	a := bit("a", ValueChange{0, "0"}, ValueChange{10, "1"}, ValueChange{30, "z"})
+	b := bit("b", ValueChange{5, "1"}, ValueChange{10, "1"}, ValueChange{20, "0"})
+	c := bit("c")
+
+	tests := []struct {
+		name string
+		bits []*SignalData
+		want string
+	}{
+		{"MSB first", []*SignalData{a, b}, "[{0 0x} {5 01} {10 11} {20 10} {30 z0}]"},
+		{"LSB first", []*SignalData{b, a}, "[{0 x0} {5 10} {10 11} {20 01} {30 0z}]"},
+		{"bit without changes", []*SignalData{c, b}, "[{5 x1} {20 x0}]"},
+		{"no bits", nil, "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CombineBits("bus", tt.bits)
+			if s := fmt.Sprint(got.Changes); s != tt.want {
+				t.Errorf("changes %s, want %s", s, tt.want)
+			}
+			if got.Signal.Width != len(tt.bits) || got.Signal.FullName != "bus" {
+				t.Errorf("signal %+v", got.Signal)
+			}
+		})
+	}
+}
+
+func TestAppendDerived(t *testing.T) {
+	bus := &SignalData{
+		Signal:  Signal{Name: "data", Width: 2, FullName: "data"},
+		Changes: []ValueChange{{0, "00"}, {10, "01"}, {10, "11"}, {20, "10"}, {20, "11"}, {30, "x1"}},
+	}
+	a := &SignalData{
+		Signal:  Signal{Name: "a", Width: 1, FullName: "a"},
+		Changes: []ValueChange{{0, "1"}, {15, "0"}, {20, "1"}, {30, "1"}},
+	}
+
+	// Grow both signals a change at a time and extend the derived ones
+	for bit := range 2 {
+		want := ExtractBit(bus, bit)
+		got := ExtractBit(&SignalData{Signal: bus.Signal}, bit)
+		for i := range bus.Changes {
+			AppendBit(got, &SignalData{Signal: bus.Signal, Changes: bus.Changes[:i+1]}, bit, i)
+		}
+		if fmt.Sprint(got.Changes) != fmt.Sprint(want.Changes) {
+			t.Errorf("bit %d: %v, want %v", bit, got.Changes, want.Changes)
+		}
+	}
+
+	// Steps of the follower: how many changes of each signal were read,
+	// including reads that end between changes at the same time
+	want := CombineBits("bus", []*SignalData{bus, a})
+	got := CombineBits("bus", []*SignalData{{Signal: bus.Signal}, {Signal: a.Signal}})
+	from := []int{0, 0}
+	for _, read := range [][]int{{1, 1}, {2, 1}, {3, 1}, {3, 2}, {4, 3}, {5, 3}, {6, 3}, {6, 4}} {
+		bits := []*SignalData{
+			{Signal: bus.Signal, Changes: bus.Changes[:read[0]]},
+			{Signal: a.Signal, Changes: a.Changes[:read[1]]},
+		}
+		AppendCombined(got, bits, from)
+		from = read
+	}
+	if fmt.Sprint(got.Changes) != fmt.Sprint(want.Changes) {
+		t.Errorf("combined: %v, want %v", got.Changes, want.Changes)
+	}
+}
@@ -0,0 +1,32 @@
+// Package waveform parses VCD (Value Change Dump) files and queries their
+// signals. It is the library behind the sigscope viewer and its list and
+// query commands.
+//
+// Parse reads a dump from a file and ParseReader from any stream; both
+// accept gzip, zstd and xz compressed input and parse the value changes on
+// all CPUs. A Follower parses a dump that a running simulation is still
+// writing. A Cache keeps parsed dumps on disk, so that parsing an unchanged
+// file again loads it instead; DefaultCache is the one sigscope itself uses.
+//
+//	v, err := waveform.Parse("sim.vcd")
+//	if err != nil {
+//		return err
+//	}
+//	clk := v.Lookup("top.clk")
+//	for _, change := range clk.ChangesIn(100, 200) {
+//		fmt.Println(change.Time, change.Value)
+//	}
+//
+// Lookup finds a signal by its full hierarchical name and Match by parts
+// of it. GetValueAt returns a signal's value at any time, ChangesIn its
+// changes in a time range, and NextChange and PrevChange its neighboring
+// changes; all binary search the changes, which are in time order. A
+// Cursor walks the changes forward through a time window. Summarize
+// describes all the changes in a range at once (transitions, min/max, any
+// x or z) from summaries built on first use, without visiting each change.
+// ExtractBit and CombineBits derive signals from the bits of others.
+//
+// Query and List build the results of the query and list commands, which
+// encode to their JSON output; DetectClock, BuildDefs, BuildInit and
+// BuildEvents build the parts of a query result on their own.
+package waveform
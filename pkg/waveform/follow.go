@@ -0,0 +1,100 @@
+package waveform
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrRewritten is returned by Follower.Update when the file got shorter or
+// was replaced by another file, e.g. because the simulation was restarted;
+// the file must be parsed again
+var ErrRewritten = errors.New("file was rewritten")
+
+// Follower parses a VCD file that is still being written. Each update
+// parses only the bytes appended since the last one and extends the
+// signals' changes and the end time in place. A compressed file can't be
+// read from the middle, so any change to it is reported as ErrRewritten.
+type Follower struct {
+	filename   string
+	parser     *parser
+	offset     int64       // Bytes parsed so far, always at the start of a line
+	info       os.FileInfo // File read by the last update
+	compressed bool
+}
+
+// Follow parses what has been written to a VCD file so far
+func Follow(filename string) (*Follower, error) {
+	compressed, err := IsCompressed(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	f := &Follower{filename: filename, parser: newParser(), compressed: compressed}
+	if _, err := f.Update(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// VCD returns the data parsed so far
+func (f *Follower) VCD() *VCDFile {
+	return f.parser.vcd
+}
+
+// Update parses the complete lines appended since the last update and
+// reports whether there were any
+func (f *Follower) Update() (bool, error) {
+	file, err := os.Open(f.filename)
+	if err != nil {
+		return false, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, fmt.Errorf("failed to open file: %w", err)
+	}
+	if f.compressed {
+		return f.updateCompressed(file, info)
+	}
+	if info.Size() < f.offset || (f.info != nil && !os.SameFile(info, f.info)) {
+		return false, ErrRewritten
+	}
+	f.info = info
+	if info.Size() == f.offset {
+		return false, nil
+	}
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		return false, fmt.Errorf("error reading file: %w", err)
+	}
+
+	n, err := f.parser.parse(file, true)
+	f.offset += n
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// updateCompressed parses a compressed file the first time and reports
+// ErrRewritten once it changes
+func (f *Follower) updateCompressed(file *os.File, info os.FileInfo) (bool, error) {
+	if f.info != nil {
+		if os.SameFile(info, f.info) && info.Size() == f.info.Size() && info.ModTime().Equal(f.info.ModTime()) {
+			return false, nil
+		}
+		return false, ErrRewritten
+	}
+	f.info = info
+
+	r, err := decompress(file)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", f.filename, err)
+	}
+	defer r.Close()
+	if _, err := f.parser.parse(r, false); err != nil {
+		return false, err
+	}
+	return true, nil
+}
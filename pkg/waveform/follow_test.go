@@ -0,0 +1,79 @@
+package waveform
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFollowInPieces(t *testing.T) {
+	want, err := ParseReader(strings.NewReader(icarusDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want.Timescale != "1ps" || want.Version != "Icarus Verilog" {
+		t.Fatalf("header parsed as %q, %q", want.Timescale, want.Version)
+	}
+
+	// Every split into three pieces, including ones inside header blocks
+	for i := 0; i <= len(icarusDump); i++ {
+		for j := i; j <= len(icarusDump); j += 13 {
+			path := writeFile(t, "sim.vcd", icarusDump[:i])
+			f, err := Follow(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, piece := range []string{icarusDump[i:j], icarusDump[j:]} {
+				appendFile(t, path, piece)
+				if _, err := f.Update(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := equalVCD(f.VCD(), want); diff != "" {
+				t.Fatalf("split at %d and %d: %s", i, j, diff)
+			}
+		}
+	}
+}
+
+func TestFollowUnfinishedHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		written string
+	}{
+		{"keyword only", "$timescale\n"},
+		{"value without $end", "$timescale\n\t1ps\n"},
+		{"partial $end", "$timescale\n\t1ps\n$en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, "sim.vcd", tt.written)
+			f, err := Follow(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.offset != 0 {
+				t.Errorf("offset %d after an unfinished block, want 0", f.offset)
+			}
+			appendFile(t, path, strings.TrimPrefix("$timescale\n\t1ps\n$end\n", tt.written))
+			if _, err := f.Update(); err != nil {
+				t.Fatal(err)
+			}
+			if got := f.VCD().Timescale; got != "1ps" {
+				t.Errorf("timescale %q, want 1ps", got)
+			}
+		})
+	}
+}
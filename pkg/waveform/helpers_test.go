@@ -0,0 +1,54 @@
+package waveform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testHeader = `$timescale 1ns $end
+$scope module top $end
+$var wire 1 ! clk $end
+$var wire 1 # rst $end
+$var wire 4 " data [3:0] $end
+$var wire 8 a#1 wide [7:0] $end
+$upscope $end
+$enddefinitions $end
+`
+
+// equalVCD reports how got differs from want, or "" if it doesn't
+func equalVCD(got, want *VCDFile) string {
+	switch {
+	case got.Version != want.Version:
+		return "version " + got.Version + ", want " + want.Version
+	case got.Date != want.Date:
+		return "date " + got.Date + ", want " + want.Date
+	case got.Timescale != want.Timescale:
+		return "timescale " + got.Timescale + ", want " + want.Timescale
+	case got.EndTime != want.EndTime:
+		return "different end time"
+	case len(got.Signals) != len(want.Signals):
+		return "different number of signals"
+	}
+	for id, w := range want.Signals {
+		g, ok := got.Signals[id]
+		if !ok {
+			return "missing signal " + w.Signal.FullName
+		}
+		if g.Signal != w.Signal || !reflect.DeepEqual(g.Changes, w.Changes) {
+			return "different signal " + w.Signal.FullName
+		}
+	}
+	return ""
+}
+
+// writeFile writes a file in a test's temporary directory
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
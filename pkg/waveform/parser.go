@@ -0,0 +1,239 @@
+package waveform
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Parse reads and parses a VCD file, which may be compressed (see Open)
+func Parse(filename string) (*VCDFile, error) {
+	file, err := Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parse(file)
+}
+
+// ParseReader parses a VCD stream, e.g. simulator output on a pipe, a
+// network connection or a buffer in memory. Compressed streams are
+// recognized as by Open.
+func ParseReader(r io.Reader) (*VCDFile, error) {
+	dr, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	defer dr.Close()
+
+	return parse(dr)
+}
+
+// parse reads VCD text to the end
+func parse(r io.Reader) (*VCDFile, error) {
+	p := newParser()
+	if _, err := p.parse(r, false); err != nil {
+		return nil, err
+	}
+	return p.vcd, nil
+}
+
+// parser holds the state carried from one part of a VCD file to the next
+type parser struct {
+	vcd          *VCDFile
+	currentScope []string
+	inHeader     bool
+	inComment    bool // The value changes parsed so far end inside a $comment
+	currentTime  uint64
+
+	// Signals by index, and their indices by VCD identifier, for the
+	// value change section
+	signals []*SignalData
+	index   map[string]int
+}
+
+func newParser() *parser {
+	return &parser{vcd: NewVCDFile(), inHeader: true}
+}
+
+// parse reads the rest of the header line by line, then the value changes
+// (see parseBody), and returns the number of bytes parsed. If complete is
+// set, a last line without a newline is left unparsed, as the simulator may
+// still be writing it, and so is a header block still missing its $end.
+func (p *parser) parse(r io.Reader, complete bool) (int64, error) {
+	lines := &lineReader{r: bufio.NewReaderSize(r, 64*1024), complete: complete}
+	for p.inHeader {
+		start := lines.offset
+		line, ok, err := lines.next()
+		if err != nil {
+			return lines.offset, err
+		}
+		if !ok {
+			return lines.offset, nil
+		}
+		if !p.parseHeaderLine(line, lines) && complete {
+			// Parsed again from its first line once the rest is written
+			return start, nil
+		}
+	}
+
+	n, err := p.parseBody(lines.r, complete)
+	return lines.offset + n, err
+}
+
+// lineReader reads lines, counting the bytes read
+type lineReader struct {
+	r        *bufio.Reader
+	offset   int64
+	complete bool // Leave a last line without a newline unread
+}
+
+// next returns the next line without surrounding space, or false at the end
+func (l *lineReader) next() (string, bool, error) {
+	line, err := l.r.ReadString('\n')
+	if err == io.EOF {
+		if line == "" || l.complete {
+			return "", false, nil
+		}
+	} else if err != nil {
+		return "", false, fmt.Errorf("error reading file: %w", err)
+	}
+	l.offset += int64(len(line))
+	return strings.TrimSpace(line), true, nil
+}
+
+// parseHeaderLine parses one line of the header; multi-line values read on.
+// It returns false if the input ended before a multi-line value did.
+func (p *parser) parseHeaderLine(line string, lines *lineReader) bool {
+	vcd := p.vcd
+	ended := true
+	if strings.HasPrefix(line, "$version") {
+		vcd.Version, ended = parseHeaderValue(line, lines, "$end")
+	} else if strings.HasPrefix(line, "$date") {
+		vcd.Date, ended = parseHeaderValue(line, lines, "$end")
+	} else if strings.HasPrefix(line, "$timescale") {
+		vcd.Timescale, ended = parseHeaderValue(line, lines, "$end")
+	} else if strings.HasPrefix(line, "$scope") {
+		parts := strings.Fields(line)
+		if len(parts) >= 3 {
+			p.currentScope = append(p.currentScope, parts[2])
+		}
+	} else if strings.HasPrefix(line, "$upscope") {
+		if len(p.currentScope) > 0 {
+			p.currentScope = p.currentScope[:len(p.currentScope)-1]
+		}
+	} else if strings.HasPrefix(line, "$var") {
+		sig := parseVar(line, p.currentScope)
+		if sig != nil {
+			vcd.Signals[sig.ID] = &SignalData{
+				Signal:  *sig,
+				Changes: make([]ValueChange, 0),
+			}
+		}
+	} else if strings.HasPrefix(line, "$enddefinitions") {
+		p.inHeader = false
+		p.signals = make([]*SignalData, 0, len(vcd.Signals))
+		p.index = make(map[string]int, len(vcd.Signals))
+		for id, sig := range vcd.Signals {
+			p.index[id] = len(p.signals)
+			p.signals = append(p.signals, sig)
+		}
+	}
+	return ended
+}
+
+// parseHeaderValue extracts value from header sections that may span
+// multiple lines, and reports whether the end marker was found
+func parseHeaderValue(line string, lines *lineReader, endMarker string) (string, bool) {
+	// Check if $end is on the same line
+	if strings.Contains(line, endMarker) {
+		// Extract value between keyword and $end
+		parts := strings.SplitN(line, " ", 2)
+		if len(parts) > 1 {
+			value := strings.TrimSuffix(parts[1], endMarker)
+			return strings.TrimSpace(value), true
+		}
+		return "", true
+	}
+
+	// Multi-line value
+	var values []string
+	parts := strings.SplitN(line, " ", 2)
+	if len(parts) > 1 {
+		values = append(values, strings.TrimSpace(parts[1]))
+	}
+
+	ended := false
+	for !ended {
+		nextLine, ok, err := lines.next()
+		if err != nil || !ok {
+			break
+		}
+		if strings.Contains(nextLine, endMarker) {
+			nextLine = strings.TrimSuffix(nextLine, endMarker)
+			if trimmed := strings.TrimSpace(nextLine); trimmed != "" {
+				values = append(values, trimmed)
+			}
+			ended = true
+			continue
+		}
+		values = append(values, nextLine)
+	}
+
+	return strings.Join(values, " "), ended
+}
+
+// parseVar parses a $var line and returns a Signal
+func parseVar(line string, currentScope []string) *Signal {
+	// $var wire 1 ! clk $end
+	// $var wire 8 " data [7:0] $end
+	parts := strings.Fields(line)
+	if len(parts) < 5 {
+		return nil
+	}
+
+	// parts[0] = "$var"
+	// parts[1] = type (wire, reg, etc.)
+	// parts[2] = width
+	// parts[3] = id
+	// parts[4:] = name (may include [7:0]) and $end
+
+	width, err := strconv.Atoi(parts[2])
+	if err != nil {
+		width = 1
+	}
+
+	id := parts[3]
+
+	// Find name - everything after id until $end
+	nameEndIdx := len(parts)
+	for i := 4; i < len(parts); i++ {
+		if parts[i] == "$end" {
+			nameEndIdx = i
+			break
+		}
+	}
+
+	name := strings.Join(parts[4:nameEndIdx], " ")
+	// Remove bit range from name if present (e.g., "[7:0]")
+	if idx := strings.Index(name, " ["); idx != -1 {
+		name = name[:idx]
+	}
+
+	scope := strings.Join(currentScope, ".")
+	fullName := name
+	if scope != "" {
+		fullName = scope + "." + name
+	}
+
+	return &Signal{
+		ID:       id,
+		Name:     name,
+		Width:    width,
+		Scope:    scope,
+		FullName: fullName,
+	}
+}
@@ -0,0 +1,148 @@
+package waveform
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// icarusDump has the multi-line header blocks Icarus Verilog writes
+const icarusDump = `$date
+	Sat Oct 18 12:00:00 2026
+$end
+$version
+	Icarus Verilog
+$end
+$timescale
+	1ps
+$end
+$scope module tb $end
+$var wire 1 ! clk $end
+$var wire 8 " data [7:0] $end
+$upscope $end
+$enddefinitions $end
+#0
+$dumpvars
+0!
+b0 "
+$end
+#5
+1!
+#10
+0!
+b101 "
+#15
+1!
+#20
+0!
+bx "
+`
+
+func TestParseReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		dump    string
+		header  [3]string // Version, date and timescale
+		signals []Signal
+		end     uint64
+	}{
+		{
+			name:   "one line blocks",
+			dump:   "$version sim 1.0 $end\n$date today $end\n$timescale 10ps $end\n$var wire 1 ! clk $end\n$enddefinitions $end\n#0\n1!\n#25\n0!\n",
+			header: [3]string{"sim 1.0", "today", "10ps"},
+			signals: []Signal{
+				{ID: "!", Name: "clk", Width: 1, FullName: "clk"},
+			},
+			end: 25,
+		},
+		{
+			name:   "multi-line blocks",
+			dump:   icarusDump,
+			header: [3]string{"Icarus Verilog", "Sat Oct 18 12:00:00 2026", "1ps"},
+			signals: []Signal{
+				{ID: "!", Name: "clk", Width: 1, Scope: "tb", FullName: "tb.clk"},
+				{ID: `"`, Name: "data", Width: 8, Scope: "tb", FullName: "tb.data"},
+			},
+			end: 20,
+		},
+		{
+			name: "nested scopes",
+			dump: "$scope module top $end\n$scope module cpu $end\n$var reg 32 % pc [31:0] $end\n$upscope $end\n" +
+				"$var wire 1 & irq $end\n$upscope $end\n$var wire x ' odd $end\n$var wire 1 $end\n$enddefinitions $end\n",
+			signals: []Signal{
+				{ID: "%", Name: "pc", Width: 32, Scope: "top.cpu", FullName: "top.cpu.pc"},
+				{ID: "&", Name: "irq", Width: 1, Scope: "top", FullName: "top.irq"},
+				{ID: "'", Name: "odd", Width: 1, FullName: "odd"},
+			},
+		},
+		{
+			name:   "header only",
+			dump:   "$timescale 1us $end\n",
+			header: [3]string{"", "", "1us"},
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := ParseReader(strings.NewReader(tt.dump))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := [3]string{v.Version, v.Date, v.Timescale}; got != tt.header {
+				t.Errorf("header %q, want %q", got, tt.header)
+			}
+			if len(v.Signals) != len(tt.signals) {
+				t.Errorf("%d signals, want %d", len(v.Signals), len(tt.signals))
+			}
+			for _, want := range tt.signals {
+				if got, ok := v.Signals[want.ID]; !ok || got.Signal != want {
+					t.Errorf("signal %q parsed as %+v, want %+v", want.ID, got, want)
+				}
+			}
+			if v.EndTime != tt.end {
+				t.Errorf("end time %d, want %d", v.EndTime, tt.end)
+			}
+		})
+	}
+}
+
+func TestParseReaderCompressed(t *testing.T) {
+	path := writeFile(t, "sim.vcd", icarusDump)
+	want, err := Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{"gzip", "zstd", "xz"} {
+		data := compressed(t, format, icarusDump)
+		got, err := ParseReader(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if diff := equalVCD(got, want); diff != "" {
+			t.Errorf("%s stream: %s", format, diff)
+		}
+
+		// Read a few bytes at a time, as from a pipe
+		got, err = ParseReader(iotest.OneByteReader(strings.NewReader(data)))
+		if err != nil {
+			t.Fatalf("%s, one byte at a time: %v", format, err)
+		}
+		if diff := equalVCD(got, want); diff != "" {
+			t.Errorf("%s stream, one byte at a time: %s", format, diff)
+		}
+	}
+}
+
+func TestParseReaderError(t *testing.T) {
+	failure := errors.New("connection reset")
+	for _, n := range []int{0, 100, len(icarusDump) - 10} {
+		r := io.MultiReader(strings.NewReader(icarusDump[:n]), iotest.ErrReader(failure))
+		if _, err := ParseReader(r); !errors.Is(err, failure) {
+			t.Errorf("error after %d bytes: got %v", n, err)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package waveform
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// QueryResult is a time range of a dump in differential event format:
+// signal definitions, the values at the start of the range and then only
+// the signals that change at each time. It is the output of the query
+// command and encodes to the same JSON.
+type QueryResult struct {
+	Timescale string               `json:"timescale"`
+	Defs      map[string]SignalDef `json:"defs"`
+	Clock     *ClockInfo           `json:"clock,omitempty"`
+	Init      map[string]string    `json:"init"`
+	Events    []Event              `json:"events"`
+}
+
+// SignalDef contains signal definition metadata
+type SignalDef struct {
+	Width int    `json:"w"`
+	Radix string `json:"radix,omitempty"` // "hex" or "bin" for multi-bit signals
+}
+
+// ClockInfo contains detected clock information
+type ClockInfo struct {
+	Name   string `json:"name"`
+	Period uint64 `json:"period"`
+	Edge   string `json:"edge"` // "posedge" or "negedge"
+}
+
+// Event represents a timestamped set of signal changes
+type Event struct {
+	Time uint64            `json:"t"`
+	Set  map[string]string `json:"set"`
+}
+
+// ListResult summarizes the signals of a dump; it is the output of the
+// list command
+type ListResult struct {
+	Signals   []SignalInfo `json:"signals"`
+	Timescale string       `json:"timescale"`
+	TimeRange [2]uint64    `json:"time_range"`
+}
+
+// SignalInfo contains signal metadata
+type SignalInfo struct {
+	Name  string `json:"name"`
+	Width int    `json:"width"`
+}
+
+// List returns the signals of a dump, sorted by full name
+func List(v *VCDFile) ListResult {
+	signalList := v.GetSignalList()
+	signals := make([]SignalInfo, 0, len(signalList))
+	for _, sig := range signalList {
+		signals = append(signals, SignalInfo{
+			Name:  sig.Signal.FullName,
+			Width: sig.Signal.Width,
+		})
+	}
+
+	return ListResult{
+		Signals:   signals,
+		Timescale: v.Timescale,
+		TimeRange: [2]uint64{0, v.EndTime},
+	}
+}
+
+// Query builds the result for the signals matching the patterns (see
+// Match) in the time range [start, end]. The clock is detected among all
+// signals, not just the matched ones, and left out of the events.
+func Query(v *VCDFile, patterns []string, start, end uint64) (*QueryResult, error) {
+	if start > end {
+		return nil, fmt.Errorf("invalid time range: start (%d) > end (%d)", start, end)
+	}
+
+	signals := v.Match(patterns)
+	clock := DetectClock(v.GetSignalList(), start, end)
+
+	return &QueryResult{
+		Timescale: v.Timescale,
+		Defs:      BuildDefs(signals),
+		Clock:     clock,
+		Init:      BuildInit(signals, start),
+		Events:    BuildEvents(signals, start, end, clock),
+	}, nil
+}
+
+// DetectClock returns the first 1-bit signal that toggles periodically in
+// the time range, or nil
+func DetectClock(signals []*SignalData, startTime, endTime uint64) *ClockInfo {
+	for _, sig := range signals {
+		// Only consider 1-bit signals
+		if sig.Signal.Width != 1 {
+			continue
+		}
+
+		// Collect transitions in the time range
+		changes := sig.ChangesIn(startTime, endTime)
+
+		// Need at least 3 transitions to detect a period
+		if len(changes) < 3 {
+			continue
+		}
+
+		// Find the most common interval (period/2); of equally common
+		// ones the shortest, not whichever the map yields first
+		intervalCounts := make(map[uint64]int)
+		for i := 1; i < len(changes); i++ {
+			intervalCounts[changes[i].Time-changes[i-1].Time]++
+		}
+
+		var maxCount int
+		var halfPeriod uint64
+		for interval, count := range intervalCounts {
+			if count > maxCount || (count == maxCount && interval < halfPeriod) {
+				maxCount = count
+				halfPeriod = interval
+			}
+		}
+
+		// If majority of intervals match, we found a clock
+		if maxCount >= (len(changes)-1)*2/3 {
+			// Determine edge by checking first transition value
+			edge := "posedge"
+			if len(sig.Changes) > 0 && sig.Changes[0].Value == "1" {
+				edge = "negedge"
+			}
+
+			return &ClockInfo{
+				Name:   shortName(sig.Signal.FullName),
+				Period: halfPeriod * 2,
+				Edge:   edge,
+			}
+		}
+	}
+
+	return nil
+}
+
+// BuildDefs returns the definitions of the signals by short name
+func BuildDefs(signals []*SignalData) map[string]SignalDef {
+	defs := make(map[string]SignalDef)
+
+	for _, sig := range signals {
+		def := SignalDef{
+			Width: sig.Signal.Width,
+		}
+
+		// Only set radix for multi-bit signals: binary if any value has x/z
+		if sig.Signal.Width > 1 {
+			def.Radix = "hex"
+			for _, ch := range sig.Changes {
+				if strings.ContainsAny(ch.Value, "xXzZ") {
+					def.Radix = "bin"
+					break
+				}
+			}
+		}
+
+		defs[shortName(sig.Signal.FullName)] = def
+	}
+
+	return defs
+}
+
+// BuildInit returns the values of the signals at startTime by short name
+func BuildInit(signals []*SignalData, startTime uint64) map[string]string {
+	init := make(map[string]string)
+
+	for _, sig := range signals {
+		value := sig.GetValueAt(startTime)
+		init[shortName(sig.Signal.FullName)] = formatValue(value, sig.Signal.Width)
+	}
+
+	return init
+}
+
+// change is a value change of a signal, by short name
+type change struct {
+	time   uint64
+	signal string
+	value  string
+}
+
+// BuildEvents groups the changes of the signals in the time range by time.
+// The clock, if any, is left out.
+func BuildEvents(signals []*SignalData, startTime, endTime uint64, clock *ClockInfo) []Event {
+	var changes []change
+
+	for _, sig := range signals {
+		name := shortName(sig.Signal.FullName)
+
+		// Skip clock signal
+		if clock != nil && name == clock.Name {
+			continue
+		}
+
+		for _, ch := range sig.ChangesIn(startTime, endTime) {
+			changes = append(changes, change{
+				time:   ch.Time,
+				signal: name,
+				value:  formatValue(ch.Value, sig.Signal.Width),
+			})
+		}
+	}
+
+	// Sort by time, keeping repeated changes of a signal at one time in
+	// file order so that the last one wins
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].time < changes[j].time
+	})
+
+	// Group by time
+	var events []Event
+	if len(changes) == 0 {
+		return events
+	}
+
+	currentTime := changes[0].time
+	currentSet := make(map[string]string)
+
+	for _, ch := range changes {
+		if ch.time != currentTime {
+			events = append(events, Event{
+				Time: currentTime,
+				Set:  currentSet,
+			})
+			currentTime = ch.time
+			currentSet = make(map[string]string)
+		}
+		currentSet[ch.signal] = ch.value
+	}
+
+	// Add last event
+	events = append(events, Event{
+		Time: currentTime,
+		Set:  currentSet,
+	})
+
+	return events
+}
+
+// shortName extracts the last component of a hierarchical name
+func shortName(fullName string) string {
+	idx := strings.LastIndex(fullName, ".")
+	if idx == -1 {
+		return fullName
+	}
+	return fullName[idx+1:]
+}
+
+// formatValue formats a value as hex for buses, as binary if it has x/z
+func formatValue(value string, width int) string {
+	if width == 1 {
+		return value // "0", "1", "x", "z"
+	}
+
+	// Check for x/z - return as binary
+	if strings.ContainsAny(value, "xXzZ") {
+		return value
+	}
+
+	// Convert to hex, through a big.Int for buses wider than 64 bits
+	n, ok := new(big.Int).SetString(value, 2)
+	if !ok {
+		// Fallback to binary
+		return value
+	}
+
+	return fmt.Sprintf("%X", n)
+}
@@ -0,0 +1,212 @@
+package waveform
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const queryDump = `$timescale 1ns $end
+$scope module top $end
+$var wire 1 ! clk $end
+$var wire 1 # valid $end
+$var wire 8 " data [7:0] $end
+$var wire 4 $ state [3:0] $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+0#
+b0 "
+b1 $
+#5
+1!
+#10
+0!
+1#
+b10100101 "
+#15
+1!
+#20
+0!
+0#
+bx1 $
+#25
+1!
+#30
+0!
+`
+
+func parseString(t *testing.T, dump string) *VCDFile {
+	t.Helper()
+	path := writeFile(t, "sim.vcd", dump)
+	v, err := Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestList(t *testing.T) {
+	got := List(parseString(t, queryDump))
+	want := ListResult{
+		Signals: []SignalInfo{
+			{Name: "top.clk", Width: 1},
+			{Name: "top.data", Width: 8},
+			{Name: "top.state", Width: 4},
+			{Name: "top.valid", Width: 1},
+		},
+		Timescale: "1ns",
+		TimeRange: [2]uint64{0, 30},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("list %+v, want %+v", got, want)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	v := parseString(t, queryDump)
+	got, err := Query(v, []string{"top."}, 8, 22)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &QueryResult{
+		Timescale: "1ns",
+		Defs: map[string]SignalDef{
+			"clk":   {Width: 1},
+			"valid": {Width: 1},
+			"data":  {Width: 8, Radix: "hex"},
+			"state": {Width: 4, Radix: "bin"},
+		},
+		Clock: &ClockInfo{Name: "clk", Period: 10, Edge: "posedge"},
+		Init:  map[string]string{"clk": "1", "valid": "0", "data": "0", "state": "1"},
+		// The clock is left out of the events
+		Events: []Event{
+			{Time: 10, Set: map[string]string{"valid": "1", "data": "A5"}},
+			{Time: 20, Set: map[string]string{"valid": "0", "state": "x1"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("query %+v, want %+v", got, want)
+	}
+
+	if _, err := Query(v, nil, 20, 10); err == nil {
+		t.Error("no error for a reversed time range")
+	}
+}
+
+func TestDetectClock(t *testing.T) {
+	// signal returns a 1-bit signal changing at the given times
+	signal := func(name string, times ...uint64) *SignalData {
+		sig := &SignalData{Signal: Signal{Name: name, FullName: "top." + name, Width: 1}}
+		for i, time := range times {
+			sig.Changes = append(sig.Changes, ValueChange{Time: time, Value: string("01"[i%2])})
+		}
+		return sig
+	}
+	tests := []struct {
+		name    string
+		signals []*SignalData
+		want    *ClockInfo
+	}{
+		{"regular", []*SignalData{signal("clk", 0, 5, 10, 15, 20)}, &ClockInfo{Name: "clk", Period: 10, Edge: "posedge"}},
+		{"mostly regular", []*SignalData{signal("clk", 0, 5, 10, 15, 22, 27, 32)}, &ClockInfo{Name: "clk", Period: 10, Edge: "posedge"}},
+		{"irregular", []*SignalData{signal("req", 0, 3, 10, 12, 30)}, nil},
+		{"too few changes", []*SignalData{signal("rst", 0, 5)}, nil},
+		{"first one wins", []*SignalData{signal("slow", 0, 50, 100), signal("fast", 0, 1, 2, 3)}, &ClockInfo{Name: "slow", Period: 100, Edge: "posedge"}},
+		{"equal counts, shortest wins", []*SignalData{signal("clk", 0, 7, 10, 17, 20)}, &ClockInfo{Name: "clk", Period: 6, Edge: "posedge"}},
+		{"equal counts in any order", []*SignalData{signal("clk", 0, 3, 10, 13, 20)}, &ClockInfo{Name: "clk", Period: 6, Edge: "posedge"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectClock(tt.signals, 0, 100); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("clock %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// Starting high, the first edge is falling
+	clk := signal("clk", 1, 5, 10, 15)
+	clk.Changes[0].Value, clk.Changes[1].Value = "1", "0"
+	clk.Changes[2].Value, clk.Changes[3].Value = "1", "0"
+	if got := DetectClock([]*SignalData{clk}, 0, 20); got == nil || got.Edge != "negedge" {
+		t.Errorf("clock %+v, want negedge", got)
+	}
+
+	// Buses are never clocks
+	bus := signal("bus", 0, 5, 10, 15)
+	bus.Signal.Width = 2
+	if got := DetectClock([]*SignalData{bus}, 0, 20); got != nil {
+		t.Errorf("bus detected as clock %+v", got)
+	}
+}
+
+func TestBuildEvents(t *testing.T) {
+	// Enough changes at one time for the sort to not be a stable one by luck;
+	// the last change at a time wins
+	bus := &SignalData{Signal: Signal{FullName: "top.bus", Width: 4}}
+	bus.Changes = append(bus.Changes, ValueChange{Time: 0, Value: "0"})
+	for i := 15; i >= 3; i-- {
+		bus.Changes = append(bus.Changes, ValueChange{Time: 10, Value: strconv.FormatInt(int64(i), 2)})
+	}
+	bus.Changes = append(bus.Changes, ValueChange{Time: 20, Value: "0"})
+	clk := &SignalData{Signal: Signal{FullName: "top.clk", Width: 1}, Changes: []ValueChange{
+		{Time: 0, Value: "0"},
+		{Time: 10, Value: "1"},
+		{Time: 20, Value: "0"},
+	}}
+	signals := []*SignalData{bus, clk}
+
+	tests := []struct {
+		name       string
+		start, end uint64
+		clock      *ClockInfo
+		want       []Event
+	}{
+		{"all", 0, 20, nil, []Event{
+			{Time: 0, Set: map[string]string{"bus": "0", "clk": "0"}},
+			{Time: 10, Set: map[string]string{"bus": "3", "clk": "1"}},
+			{Time: 20, Set: map[string]string{"bus": "0", "clk": "0"}},
+		}},
+		{"clock left out", 5, 20, &ClockInfo{Name: "clk", Period: 20}, []Event{
+			{Time: 10, Set: map[string]string{"bus": "3"}},
+			{Time: 20, Set: map[string]string{"bus": "0"}},
+		}},
+		{"nothing in range", 11, 19, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The order must not depend on the sort being lucky
+			for range 20 {
+				if got := BuildEvents(signals, tt.start, tt.end, tt.clock); !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("events %+v, want %+v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value string
+		width int
+		want  string
+	}{
+		{"1", 1, "1"},
+		{"z", 1, "z"},
+		{"0", 8, "0"},
+		{"10100101", 8, "A5"},
+		{"1x01", 4, "1x01"},
+		{"ZZZZ", 4, "ZZZZ"},
+		{strings.Repeat("1", 64), 64, "FFFFFFFFFFFFFFFF"},
+		{"1" + strings.Repeat("0", 79), 80, "80000000000000000000"},
+		{strings.Repeat("1", 128), 128, strings.Repeat("F", 32)},
+		{"12", 8, "12"}, // Not binary, left as it is
+	}
+	for _, tt := range tests {
+		if got := formatValue(tt.value, tt.width); got != tt.want {
+			t.Errorf("formatValue(%q, %d) = %q, want %q", tt.value, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package waveform
+
+import "strings"
+
+// Summary describes a run of value changes of a signal as a whole, e.g.
+// the changes under one character of a zoomed-out waveform
+type Summary struct {
+	Changes     int    // Number of changes
+	Transitions int    // Changes to a different value; repeats don't count
+	First       string // Value after the first transition, "" if none
+	Last        string // Value after the last change, "" if none
+	Min, Max    string // Lowest and highest value without x or z, "" if none
+	Unknown     bool   // Some value has an x
+	HighZ       bool   // Some value has a z
+}
+
+// Summaries of a signal's changes are kept in a pyramid: the first level
+// summarizes blocks of summaryBlock changes, and each level above it
+// summaryFanout entries of the level below. Summarizing any range then
+// takes a bounded number of merges however many changes it covers.
+const (
+	summaryBlock  = 64
+	summaryFanout = 8
+)
+
+// summaries is the summary pyramid of a signal's first n changes
+type summaries struct {
+	n      int
+	levels [][]Summary
+}
+
+// Summarize returns the summary of the changes in the time range
+// [start, end]. Transitions are counted from the value before start.
+// The first call for a busy signal builds its summaries, so like appending
+// changes it must not run concurrently with other uses of the signal.
+func (sd *SignalData) Summarize(start, end uint64) Summary {
+	if start > end {
+		return Summary{}
+	}
+	return sd.summarize(sd.search(start), sd.searchAfter(end))
+}
+
+// Summarize returns the summary of the changes from the cursor position to
+// time end, like those Next would return, and moves past them
+func (c *Cursor) Summarize(end uint64) Summary {
+	from := c.pos
+	c.Seek(end)
+	return c.sd.summarize(from, c.pos)
+}
+
+// summarize returns the summary of Changes[from:to]
+func (sd *SignalData) summarize(from, to int) Summary {
+	var s Summary
+	if to-from < 2*summaryBlock {
+		for i := from; i < to; i++ {
+			s = s.merge(sd.changeSummary(i))
+		}
+		return s
+	}
+
+	levels := sd.pyramid().levels
+	for i := from; i < to; {
+		if i%summaryBlock != 0 || i+summaryBlock > to {
+			s = s.merge(sd.changeSummary(i))
+			i++
+			continue
+		}
+		// The largest block starting here that fits in the range
+		level, size := 0, summaryBlock
+		for level+1 < len(levels) && i%(size*summaryFanout) == 0 && i+size*summaryFanout <= to {
+			level++
+			size *= summaryFanout
+		}
+		s = s.merge(levels[level][i/size])
+		i += size
+	}
+	return s
+}
+
+// pyramid returns the summaries of all changes, building them on first use
+// and extending them when changes were appended since
+func (sd *SignalData) pyramid() *summaries {
+	if sd.summary == nil || sd.summary.n != len(sd.Changes) {
+		sd.summary = sd.buildSummaries(sd.summary)
+	}
+	return sd.summary
+}
+
+// buildSummaries builds the summary pyramid, keeping the complete blocks
+// of an earlier one for fewer changes
+func (sd *SignalData) buildSummaries(old *summaries) *summaries {
+	n := len(sd.Changes)
+	if old != nil && old.n > n {
+		old = nil
+	}
+
+	p := &summaries{n: n}
+	size := summaryBlock
+	for level := 0; ; level++ {
+		count := (n + size - 1) / size
+		entries := make([]Summary, count)
+
+		kept := 0
+		if old != nil && level < len(old.levels) {
+			kept = copy(entries, old.levels[level][:old.n/size])
+		}
+		for i := kept; i < count; i++ {
+			if level == 0 {
+				for j := i * size; j < min((i+1)*size, n); j++ {
+					entries[i] = entries[i].merge(sd.changeSummary(j))
+				}
+				continue
+			}
+			below := p.levels[level-1]
+			for j := i * summaryFanout; j < min((i+1)*summaryFanout, len(below)); j++ {
+				entries[i] = entries[i].merge(below[j])
+			}
+		}
+
+		p.levels = append(p.levels, entries)
+		if count <= 1 {
+			return p
+		}
+		size *= summaryFanout
+	}
+}
+
+// changeSummary returns the summary of the single change Changes[i]
+func (sd *SignalData) changeSummary(i int) Summary {
+	value := sd.Changes[i].Value
+	prev := "x"
+	if i > 0 {
+		prev = sd.Changes[i-1].Value
+	}
+
+	s := Summary{Changes: 1, Last: value}
+	if value != prev {
+		s.Transitions = 1
+		s.First = value
+	}
+	s.Unknown = strings.ContainsAny(value, "xX")
+	s.HighZ = strings.ContainsAny(value, "zZ")
+	if !s.Unknown && !s.HighZ {
+		s.Min, s.Max = value, value
+	}
+	return s
+}
+
+// merge combines the summary with that of the changes right after it
+func (s Summary) merge(next Summary) Summary {
+	if next.Changes == 0 {
+		return s
+	}
+	if s.Transitions == 0 {
+		s.First = next.First
+	}
+	s.Changes += next.Changes
+	s.Transitions += next.Transitions
+	s.Last = next.Last
+	if next.Min != "" && (s.Min == "" || lessValue(next.Min, s.Min)) {
+		s.Min = next.Min
+	}
+	if next.Max != "" && (s.Max == "" || lessValue(s.Max, next.Max)) {
+		s.Max = next.Max
+	}
+	s.Unknown = s.Unknown || next.Unknown
+	s.HighZ = s.HighZ || next.HighZ
+	return s
+}
+
+// lessValue compares two binary values without x or z. Leading zeros, which
+// VCD may omit, don't matter.
+func lessValue(a, b string) bool {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
@@ -0,0 +1,118 @@
+package waveform
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	sd := &SignalData{Changes: []ValueChange{
+		{0, "x"}, {10, "0101"}, {20, "0101"}, {30, "11"}, {40, "z"}, {50, "0000"}, {60, "1x"},
+	}}
+	tests := []struct {
+		start, end uint64
+		want       Summary
+	}{
+		{0, 100, Summary{Changes: 7, Transitions: 5, First: "0101", Last: "1x", Min: "0000", Max: "0101", Unknown: true, HighZ: true}},
+		{10, 20, Summary{Changes: 2, Transitions: 1, First: "0101", Last: "0101", Min: "0101", Max: "0101"}},
+		{20, 35, Summary{Changes: 2, Transitions: 1, First: "11", Last: "11", Min: "11", Max: "0101"}},
+		{20, 20, Summary{Changes: 1, Last: "0101", Min: "0101", Max: "0101"}},
+		{35, 45, Summary{Changes: 1, Transitions: 1, First: "z", Last: "z", HighZ: true}},
+		{70, 100, Summary{}},
+		{50, 40, Summary{}},
+	}
+	for _, tt := range tests {
+		if got := sd.Summarize(tt.start, tt.end); got != tt.want {
+			t.Errorf("summary of [%d, %d]: %+v, want %+v", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestLessValue(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"0", "1", true},
+		{"1", "0", false},
+		{"1", "1", false},
+		{"0001", "10", true},
+		{"10", "01", false},
+		{"", "0", false},
+		{"0111", "1000", true},
+	}
+	for _, tt := range tests {
+		if got := lessValue(tt.a, tt.b); got != tt.want {
+			t.Errorf("lessValue(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// foldSummary merges the summaries of every single change in [from, to)
+func foldSummary(sd *SignalData, from, to int) Summary {
+	var s Summary
+	for i := from; i < to; i++ {
+		s = s.merge(sd.changeSummary(i))
+	}
+	return s
+}
+
+func TestSummarizePyramid(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sd := &SignalData{}
+	values := []string{"0", "1", "10", "11", "x", "z", "0110"}
+	appendChanges := func(n int) {
+		for range n {
+			time := uint64(len(sd.Changes))
+			sd.Changes = append(sd.Changes, ValueChange{time, values[rng.Intn(len(values))]})
+		}
+	}
+
+	// Summarized, then appended to, across several pyramid levels
+	for _, n := range []int{100, 1000, 37, 5000, 64, 30000} {
+		appendChanges(n)
+		total := len(sd.Changes)
+		for range 300 {
+			from := rng.Intn(total)
+			to := from + rng.Intn(total-from+1)
+			want := foldSummary(sd, from, to)
+			if got := sd.summarize(from, to); got != want {
+				t.Fatalf("%d changes, summary of [%d, %d): %+v, want %+v", total, from, to, got, want)
+			}
+		}
+		if got, want := sd.summarize(0, total), foldSummary(sd, 0, total); got != want {
+			t.Fatalf("%d changes, whole summary: %+v, want %+v", total, got, want)
+		}
+	}
+
+	// The cursor summarizes what Next would return
+	c := sd.Cursor(0)
+	for start := uint64(0); start < uint64(len(sd.Changes)); {
+		end := start + uint64(rng.Intn(2000))
+		c.Seek(start)
+		from := c.pos
+		got := c.Summarize(end)
+		if want := foldSummary(sd, from, c.pos); got != want || c.pos != sd.search(end) {
+			t.Fatalf("cursor summary of [%d, %d): %+v, want %+v", start, end, got, want)
+		}
+		start = end
+	}
+}
+
+func TestSummarizeCopies(t *testing.T) {
+	sd := SignalData{}
+	for i := range 1000 {
+		sd.Changes = append(sd.Changes, ValueChange{uint64(i), string("01"[i%2])})
+	}
+	total := sd.Summarize(0, 2000)
+
+	// A copy shares the pyramid until its changes grow
+	cp := sd
+	cp.Changes = append(cp.Changes[:len(cp.Changes):len(cp.Changes)], ValueChange{1000, "x"})
+	if got, want := cp.Summarize(0, 2000), foldSummary(&cp, 0, len(cp.Changes)); got != want || !got.Unknown {
+		t.Errorf("summary of the grown copy %+v, want %+v", got, want)
+	}
+	if got := sd.Summarize(0, 2000); got != total {
+		t.Errorf("summary of the original %+v after the copy grew, want %+v", got, total)
+	}
+}
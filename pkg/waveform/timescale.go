@@ -0,0 +1,77 @@
+package waveform
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// timeUnits maps time unit suffixes to their power-of-ten exponent
+var timeUnits = map[string]int{
+	"s":  0,
+	"ms": -3,
+	"us": -6,
+	"ns": -9,
+	"ps": -12,
+	"fs": -15,
+}
+
+// ParseTimescale splits a timescale such as "10ps" or "1 ns" into its
+// multiplier and the exponent of its unit (e.g. -12 for ps)
+func ParseTimescale(timescale string) (uint64, int, error) {
+	ts := strings.ReplaceAll(strings.TrimSpace(timescale), " ", "")
+	end := 0
+	for end < len(ts) && ts[end] >= '0' && ts[end] <= '9' {
+		end++
+	}
+	multiplier, err := strconv.ParseUint(ts[:end], 10, 64)
+	if err != nil || multiplier == 0 {
+		return 0, 0, fmt.Errorf("invalid timescale %q", timescale)
+	}
+	exponent, ok := timeUnits[ts[end:]]
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid timescale unit %q", timescale)
+	}
+	return multiplier, exponent, nil
+}
+
+// ParseTime parses a time in dump units. A plain integer is taken as dump
+// units; a number with a unit suffix (fs, ps, ns, us, ms, s) is converted
+// using the dump's timescale and rounded to the nearest unit. Times that
+// don't fit in 64 bits of dump units are an error.
+func ParseTime(s, timescale string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	t, err := strconv.ParseUint(s, 10, 64)
+	if err == nil {
+		return t, nil
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("time %q out of range", s)
+	}
+
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.') {
+		end++
+	}
+	value, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	exponent, ok := timeUnits[strings.TrimSpace(s[end:])]
+	if !ok {
+		return 0, fmt.Errorf("invalid time unit in %q", s)
+	}
+
+	multiplier, tsExponent, err := ParseTimescale(timescale)
+	if err != nil {
+		return 0, err
+	}
+	units := math.Round(value * math.Pow(10, float64(exponent-tsExponent)) / float64(multiplier))
+	// MaxUint64 rounds up to 2^64 as a float64
+	if units >= math.MaxUint64 {
+		return 0, fmt.Errorf("time %q out of range", s)
+	}
+	return uint64(units), nil
+}
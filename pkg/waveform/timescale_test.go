@@ -0,0 +1,75 @@
+package waveform
+
+import "testing"
+
+func TestParseTimescale(t *testing.T) {
+	tests := []struct {
+		timescale  string
+		multiplier uint64
+		exponent   int
+		wantErr    bool
+	}{
+		{"1ns", 1, -9, false},
+		{"10ps", 10, -12, false},
+		{"100 fs", 100, -15, false},
+		{" 1 s ", 1, 0, false},
+		{"1us", 1, -6, false},
+		{"10ms", 10, -3, false},
+		{"", 0, 0, true},
+		{"ns", 0, 0, true},
+		{"0ns", 0, 0, true},
+		{"1 ks", 0, 0, true},
+		{"1.5ns", 0, 0, true},
+	}
+	for _, tt := range tests {
+		multiplier, exponent, err := ParseTimescale(tt.timescale)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTimescale(%q): error %v", tt.timescale, err)
+			continue
+		}
+		if multiplier != tt.multiplier || exponent != tt.exponent {
+			t.Errorf("ParseTimescale(%q) = %d, %d, want %d, %d",
+				tt.timescale, multiplier, exponent, tt.multiplier, tt.exponent)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		s, timescale string
+		want         uint64
+		wantErr      bool
+	}{
+		{"1500", "1ns", 1500, false},
+		{" 42 ", "", 42, false}, // Dump units need no timescale
+		{"2.5us", "1ns", 2500, false},
+		{"2.5 us", "1ns", 2500, false},
+		{"1ns", "10ps", 100, false},
+		{"15ps", "10ps", 2, false}, // Rounded to the nearest unit
+		{"14ps", "10ps", 1, false},
+		{".5ns", "1ps", 500, false},
+		{"1s", "1fs", 1000000000000000, false},
+		{"3ns", "1us", 0, false},
+		{"1ns", "", 0, true},
+		{"2.5", "1ns", 0, true},
+		{"abc", "1ns", 0, true},
+		{"5 minutes", "1ns", 0, true},
+		{"1.2.3ns", "1ns", 0, true},
+		{"-5ns", "1ns", 0, true},
+		{"18446744073709551615", "1ns", 1<<64 - 1, false},
+		{"18446744073709551616", "1ns", 0, true},
+		{"10000s", "1fs", 10000000000000000000, false},
+		{"100000s", "1fs", 0, true}, // Past 64 bits of femtoseconds
+		{"0.0000001ns", "1ns", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.s, tt.timescale)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTime(%q, %q): error %v", tt.s, tt.timescale, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTime(%q, %q) = %d, want %d", tt.s, tt.timescale, got, tt.want)
+		}
+	}
+}
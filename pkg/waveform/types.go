@@ -0,0 +1,110 @@
+package waveform
+
+import (
+	"sort"
+	"strings"
+)
+
+// Signal represents a VCD signal definition
+type Signal struct {
+	ID       string // VCD identifier (e.g., "!", "#", etc.)
+	Name     string // Signal name
+	Width    int    // Bit width (1 for single bit, >1 for bus)
+	Scope    string // Hierarchical scope (e.g., "top.module")
+	FullName string // Scope + Name
+}
+
+// ValueChange represents a value change event
+type ValueChange struct {
+	Time  uint64 // Time in timescale units
+	Value string // Value (binary string for buses, "0"/"1"/"x"/"z" for single bit)
+}
+
+// SignalData contains a signal definition and its value changes
+type SignalData struct {
+	Signal  Signal
+	Changes []ValueChange // In time order
+
+	// Summary pyramid, built on first use by Summarize. Pyramids are never
+	// changed once built, so copies of a SignalData may share one.
+	summary *summaries
+}
+
+// VCDFile represents a parsed VCD file
+type VCDFile struct {
+	Version   string
+	Date      string
+	Timescale string
+	Signals   map[string]*SignalData // Key: signal ID
+	EndTime   uint64                 // Maximum time in the file
+}
+
+// NewVCDFile creates a new VCDFile instance
+func NewVCDFile() *VCDFile {
+	return &VCDFile{
+		Signals: make(map[string]*SignalData),
+	}
+}
+
+// GetSignalList returns all signals as a slice, sorted by full name
+func (v *VCDFile) GetSignalList() []*SignalData {
+	result := make([]*SignalData, 0, len(v.Signals))
+	for _, sig := range v.Signals {
+		result = append(result, sig)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Signal.FullName < result[j].Signal.FullName
+	})
+	return result
+}
+
+// Lookup returns the signal with the given full name (e.g. "top.u_dma.state"),
+// or nil if there is none
+func (v *VCDFile) Lookup(fullName string) *SignalData {
+	for _, sig := range v.Signals {
+		if sig.Signal.FullName == fullName {
+			return sig
+		}
+	}
+	return nil
+}
+
+// Match returns the signals whose full name contains any of the patterns,
+// sorted by full name. No patterns match every signal.
+func (v *VCDFile) Match(patterns []string) []*SignalData {
+	all := v.GetSignalList()
+	if len(patterns) == 0 {
+		return all
+	}
+
+	var matched []*SignalData
+	for _, sig := range all {
+		for _, pattern := range patterns {
+			if strings.Contains(sig.Signal.FullName, pattern) {
+				matched = append(matched, sig)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// GetValueAt returns the value of a signal at a given time, "x" before
+// its first change
+func (sd *SignalData) GetValueAt(time uint64) string {
+	// The last change at or before the given time
+	i := sd.searchAfter(time)
+	if i == 0 {
+		return "x"
+	}
+	return sd.Changes[i-1].Value
+}
+
+// ChangesIn returns the changes in the time range [start, end]. The result
+// shares its storage with Changes and must not be modified.
+func (sd *SignalData) ChangesIn(start, end uint64) []ValueChange {
+	if start > end {
+		return nil
+	}
+	return sd.Changes[sd.search(start):sd.searchAfter(end)]
+}